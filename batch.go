@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// BatchOptions options of BatchRun and BatchMap
+type BatchOptions struct {
+	BatchSize   int // <=0 means 100, like BatchCall
+	Concurrency int // the number of batches running at the same time, <=0 means 1
+	// AggregateErrors runs all batches and joins their errors, otherwise the first error cancels the
+	// batches not started yet and is returned.
+	AggregateErrors bool
+	Retries         int              // retry times of a failed batch
+	RetryBackoff    time.Duration    // the delay before the first retry, doubled for each retry
+	MaxBackoff      time.Duration    // the max delay between retries, 0 means no limit
+	Retryable       func(error) bool // nil means all errors are retryable
+	// OnProgress is called after each batch is finished or cancelled, and once more for the batches
+	// not started after the context is done. Calls are serialized.
+	OnProgress func(p BatchProgress)
+}
+
+// BatchProgress counts of batches
+type BatchProgress struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Cancelled int // batches never run because the context is done or cancelled by the first error
+	Items     int // the number of items in finished batches
+}
+
+func (p BatchProgress) Done() int { return p.Succeeded + p.Failed }
+
+// BatchError the error of a batch, Offset is the index of its first item in the input
+type BatchError struct {
+	Batch    int
+	Offset   int
+	Attempts int
+	Err      error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("tools: batch %d (offset %d) failed after %d attempts: %v", e.Batch, e.Offset, e.Attempts, e.Err)
+}
+
+func (e *BatchError) Unwrap() error { return e.Err }
+
+func (o BatchOptions) backoff(retry int) time.Duration {
+	d := o.RetryBackoff
+	for i := 1; i < retry && d > 0; i++ {
+		if d > math.MaxInt64/2 {
+			d = math.MaxInt64
+			break
+		}
+		d *= 2
+		if o.MaxBackoff > 0 && d >= o.MaxBackoff {
+			break
+		}
+	}
+	if o.MaxBackoff > 0 && d > o.MaxBackoff {
+		d = o.MaxBackoff
+	}
+	return d
+}
+
+func runBatch[T, R any](ctx context.Context, opts BatchOptions, op func(context.Context, []T) ([]R, error),
+	batch []T) (rs []R, attempts int, err error) {
+	for attempts = 1; ; attempts++ {
+		if err = ctx.Err(); err != nil {
+			return nil, attempts - 1, err
+		}
+		if rs, err = op(ctx, batch); err == nil {
+			return rs, attempts, nil
+		}
+		if attempts > opts.Retries || (opts.Retryable != nil && !opts.Retryable(err)) {
+			return nil, attempts, err
+		}
+		if d := opts.backoff(attempts); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, attempts, err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// cancelledByFirstErr reports whether err is caused by cancelling runCtx after the first error
+// rather than by ctx
+func cancelledByFirstErr(ctx, runCtx context.Context, err error) bool {
+	return runCtx.Err() != nil && ctx.Err() == nil &&
+		(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded))
+}
+
+// BatchMap splits ts into batches and runs op on them with bounded concurrency, returns results of
+// succeeded batches in the order of ts. The returned error is a *BatchError (joined by errors.Join
+// with AggregateErrors), or the error of ctx if it's done before all batches are started.
+func BatchMap[T, R any](ctx context.Context, opts BatchOptions, op func(ctx context.Context, batch []T) ([]R, error),
+	ts ...T) ([]R, error) {
+	pages := Pages(ts, opts.BatchSize)
+	if len(pages) == 0 {
+		return nil, ctx.Err()
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]R, len(pages))
+	errs := make([]error, len(pages))
+	offsets := make([]int, len(pages))
+	for i := 1; i < len(pages); i++ {
+		offsets[i] = offsets[i-1] + len(pages[i-1])
+	}
+	var (
+		lock     sync.Mutex
+		progress = BatchProgress{Total: len(pages)}
+		firstErr error
+		jobs     = make(chan int)
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < min(max(opts.Concurrency, 1), len(pages)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rs, attempts, err := runBatch(runCtx, opts, op, pages[i])
+				lock.Lock()
+				switch {
+				case err != nil && (attempts == 0 || cancelledByFirstErr(ctx, runCtx, err)):
+					// cancelled before the first attempt, or failed only because of the first error
+					progress.Cancelled++
+				case err != nil:
+					errs[i] = &BatchError{Batch: i, Offset: offsets[i], Attempts: attempts, Err: err}
+					if firstErr == nil {
+						firstErr = errs[i]
+						if !opts.AggregateErrors {
+							cancel()
+						}
+					}
+					progress.Failed++
+					progress.Items += len(pages[i])
+				default:
+					results[i] = rs
+					progress.Succeeded++
+					progress.Items += len(pages[i])
+				}
+				if opts.OnProgress != nil {
+					opts.OnProgress(progress)
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	fed := 0
+feed:
+	for i := range pages {
+		select {
+		case jobs <- i:
+			fed++
+		case <-runCtx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if fed < len(pages) {
+		progress.Cancelled += len(pages) - fed
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+
+	var ret []R
+	for _, rs := range results {
+		ret = append(ret, rs...)
+	}
+	var ctxErr error
+	if progress.Cancelled > 0 {
+		ctxErr = ctx.Err()
+	}
+	if !opts.AggregateErrors {
+		if firstErr != nil {
+			return ret, firstErr
+		}
+		return ret, ctxErr
+	}
+	return ret, errors.Join(append(errs, ctxErr)...)
+}
+
+// BatchRun is BatchMap without results
+func BatchRun[T any](ctx context.Context, opts BatchOptions, op func(ctx context.Context, batch []T) error, ts ...T) error {
+	_, err := BatchMap(ctx, opts, func(ctx context.Context, batch []T) ([]struct{}, error) {
+		return nil, op(ctx, batch)
+	}, ts...)
+	return err
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchMap(t *testing.T) {
+	ts := make([]int, 95)
+	for i := range ts {
+		ts[i] = i
+	}
+	var running, maxRunning atomic.Int32
+	var progress []BatchProgress
+	rs, err := BatchMap(context.Background(), BatchOptions{
+		BatchSize:   10,
+		Concurrency: 4,
+		OnProgress:  func(p BatchProgress) { progress = append(progress, p) },
+	}, func(ctx context.Context, batch []int) ([]int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(10-batch[0]/10) * time.Millisecond)
+		return SsToTs(func(i int) int { return i * 2 }, batch...), nil
+	}, ts...)
+	if err != nil || len(rs) != 95 || rs[94] != 188 || !slices.IsSorted(rs) {
+		t.Fatalf("results: %v %v", rs, err)
+	}
+	if maxRunning.Load() > 4 || maxRunning.Load() < 2 {
+		t.Fatalf("concurrency: %d", maxRunning.Load())
+	}
+	last := progress[len(progress)-1]
+	if len(progress) != 10 || last.Done() != 10 || last.Items != 95 || last.Total != 10 {
+		t.Fatalf("progress: %+v", last)
+	}
+}
+
+func TestBatchOptions_Backoff(t *testing.T) {
+	opts := BatchOptions{RetryBackoff: time.Millisecond}
+	for _, retry := range []int{1, 40, 64, 1000} {
+		if d := opts.backoff(retry); d <= 0 {
+			t.Fatalf("retry %d: %s", retry, d)
+		}
+	}
+	opts.MaxBackoff = time.Second
+	if d := opts.backoff(100); d != time.Second {
+		t.Fatalf("max backoff: %s", d)
+	}
+}
+
+func TestBatchRun_Errors(t *testing.T) {
+	errBad := errors.New("bad batch")
+	ts := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	var calls atomic.Int32
+	err := BatchRun(context.Background(), BatchOptions{BatchSize: 2}, func(ctx context.Context, batch []int) error {
+		calls.Add(1)
+		if batch[0] == 4 {
+			return errBad
+		}
+		return nil
+	}, ts...)
+	var be *BatchError
+	if !errors.As(err, &be) || be.Batch != 2 || be.Offset != 4 || be.Attempts != 1 || !errors.Is(err, errBad) {
+		t.Fatalf("first error: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("batches after the first error should be cancelled: %d", calls.Load())
+	}
+
+	// batches running when the first error happens are cancelled, not failed
+	var last BatchProgress
+	started := make(chan struct{}, 2)
+	err = BatchRun(context.Background(), BatchOptions{BatchSize: 2, Concurrency: 3, OnProgress: func(p BatchProgress) { last = p }},
+		func(ctx context.Context, batch []int) error {
+			if batch[0] == 0 {
+				<-started
+				<-started
+				return errBad
+			}
+			started <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		}, ts...)
+	if !errors.As(err, &be) || be.Batch != 0 || last.Failed != 1 || last.Succeeded != 0 || last.Cancelled != 4 {
+		t.Fatalf("cancelled by the first error: %v %+v", err, last)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = BatchRun(ctx, BatchOptions{BatchSize: 2, AggregateErrors: true, OnProgress: func(p BatchProgress) { last = p }},
+		func(ctx context.Context, batch []int) error {
+			if batch[0] == 2 {
+				cancel()
+			}
+			return nil
+		}, ts...)
+	if !errors.Is(err, context.Canceled) || errors.As(err, &be) || last.Failed != 0 || last.Succeeded != 2 || last.Cancelled != 3 {
+		t.Fatalf("cancelled batches: %v %+v", err, last)
+	}
+
+	err = BatchRun(context.Background(), BatchOptions{BatchSize: 2, Concurrency: 3, AggregateErrors: true},
+		func(ctx context.Context, batch []int) error {
+			if batch[0]%4 == 0 {
+				return errBad
+			}
+			return nil
+		}, ts...)
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 3 {
+		t.Fatalf("aggregate: %v", err)
+	}
+
+	var attempts atomic.Int32
+	errTemp := errors.New("temporary")
+	start := time.Now()
+	err = BatchRun(context.Background(), BatchOptions{
+		BatchSize:    5,
+		Retries:      3,
+		RetryBackoff: time.Millisecond,
+		MaxBackoff:   2 * time.Millisecond,
+		Retryable:    func(err error) bool { return errors.Is(err, errTemp) },
+	}, func(ctx context.Context, batch []int) error {
+		if batch[0] == 0 && attempts.Add(1) < 3 {
+			return errTemp
+		}
+		return nil
+	}, ts...)
+	if err != nil || attempts.Load() != 3 || time.Since(start) < 3*time.Millisecond {
+		t.Fatalf("retry: %v %d", err, attempts.Load())
+	}
+	attempts.Store(0)
+	err = BatchRun(context.Background(), BatchOptions{Retries: 3, Retryable: func(err error) bool { return errors.Is(err, errTemp) }},
+		func(ctx context.Context, batch []int) error {
+			attempts.Add(1)
+			return errBad
+		}, ts...)
+	if !errors.As(err, &be) || be.Attempts != 1 || attempts.Load() != 1 {
+		t.Fatalf("not retryable: %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = BatchRun(ctx, BatchOptions{BatchSize: 1}, func(ctx context.Context, batch []int) error { return nil }, ts...)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled: %v", err)
+	}
+	if err = BatchRun(ctx, BatchOptions{}, func(ctx context.Context, batch []int) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("empty input with cancelled context: %v", err)
+	}
+}
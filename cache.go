@@ -0,0 +1,423 @@
+package tools
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type CachePolicy int
+
+const (
+	CacheLRU CachePolicy = iota // least recently used
+	CacheLFU                    // least frequently used
+	CacheARC                    // adaptive replacement cache
+)
+
+func (p CachePolicy) String() string {
+	switch p {
+	case CacheLRU:
+		return "LRU"
+	case CacheLFU:
+		return "LFU"
+	case CacheARC:
+		return "ARC"
+	default:
+		return "CachePolicy-" + fmt.Sprint(int(p))
+	}
+}
+
+// EvictReason why an entry left the cache
+type EvictReason int
+
+const (
+	EvictCapacity EvictReason = iota // exceeded Capacity or MaxWeight
+	EvictExpired                     // TTL expired
+	EvictDeleted                     // Delete or Purge
+	EvictReplaced                    // the value was replaced by Set
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	case EvictReplaced:
+		return "replaced"
+	default:
+		return "EvictReason-" + fmt.Sprint(int(r))
+	}
+}
+
+type CacheOptions[K comparable, V any] struct {
+	Policy CachePolicy
+	// Capacity max number of entries, 0 means no limit
+	Capacity int
+	// MaxWeight max sum of the weights of entries, 0 means no limit
+	MaxWeight int64
+	// Weigher returns the weight of an entry, 1 if nil
+	Weigher func(k K, v V) int64
+	// TTL default time to live of entries, 0 means never expire. Expired entries are removed when
+	// accessed, or by the background cleaner if CleanupInterval > 0.
+	TTL             time.Duration
+	CleanupInterval time.Duration
+	// OnEvict is called after an entry left the cache, outside the lock of the cache
+	OnEvict func(k K, v V, reason EvictReason)
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+}
+
+type CacheStats struct {
+	Hits        int64
+	Misses      int64
+	Evictions   int64 // evicted by capacity or weight
+	Expirations int64
+	Loads       int64 // calls of loaders
+	LoadErrors  int64
+}
+
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("CacheStats{Hits:%d Misses:%d HitRate:%.4f Evictions:%d Expirations:%d Loads:%d LoadErrors:%d}",
+		s.Hits, s.Misses, s.HitRate(), s.Evictions, s.Expirations, s.Loads, s.LoadErrors)
+}
+
+type cacheEntry[V any] struct {
+	value    V
+	weight   int64
+	expireAt time.Time // zero for never
+}
+
+type cacheEvicted[K comparable, V any] struct {
+	k      K
+	v      V
+	reason EvictReason
+}
+
+type cacheCall[V any] struct {
+	wg  sync.WaitGroup
+	v   V
+	err error
+}
+
+// Cache 并发安全的缓存，支持LRU/LFU/ARC淘汰、TTL、按权重限制容量、淘汰回调、命中统计，以及合并并发加载的
+// GetOrLoad
+type Cache[K comparable, V any] struct {
+	opts    CacheOptions[K, V]
+	lock    sync.Mutex
+	entries map[K]*cacheEntry[V]
+	policy  cachePolicy[K]
+	weight  int64
+	stats   CacheStats
+	calls   map[K]*cacheCall[V]
+	stop    chan struct{}
+	once    sync.Once
+}
+
+func NewCache[K comparable, V any](opts CacheOptions[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		opts:    opts,
+		entries: make(map[K]*cacheEntry[V]),
+		calls:   make(map[K]*cacheCall[V]),
+	}
+	switch opts.Policy {
+	case CacheLFU:
+		c.policy = newLFUPolicy[K]()
+	case CacheARC:
+		c.policy = newARCPolicy[K](opts.Capacity)
+	default:
+		c.policy = newLRUPolicy[K]()
+	}
+	if opts.CleanupInterval > 0 {
+		c.stop = make(chan struct{})
+		go c.cleanup(opts.CleanupInterval)
+	}
+	return c
+}
+
+// NewLRUCache creates a LRU cache with the max number of entries
+func NewLRUCache[K comparable, V any](capacity int) *Cache[K, V] {
+	return NewCache(CacheOptions[K, V]{Policy: CacheLRU, Capacity: capacity})
+}
+
+func (c *Cache[K, V]) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Close stops the background cleaner
+func (c *Cache[K, V]) Close() {
+	c.once.Do(func() {
+		if c.stop != nil {
+			close(c.stop)
+		}
+	})
+}
+
+func (c *Cache[K, V]) now() time.Time {
+	if c.opts.Now != nil {
+		return c.opts.Now()
+	}
+	return time.Now()
+}
+
+func (c *Cache[K, V]) notify(evicted []cacheEvicted[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		c.opts.OnEvict(e.k, e.v, e.reason)
+	}
+}
+
+// removeLocked removes k from the cache, the policy should have been updated by the caller
+func (c *Cache[K, V]) removeLocked(k K, reason EvictReason, evicted []cacheEvicted[K, V]) []cacheEvicted[K, V] {
+	e, ok := c.entries[k]
+	if !ok {
+		return evicted
+	}
+	delete(c.entries, k)
+	c.weight -= e.weight
+	switch reason {
+	case EvictCapacity:
+		c.stats.Evictions++
+	case EvictExpired:
+		c.stats.Expirations++
+	}
+	return append(evicted, cacheEvicted[K, V]{k: k, v: e.value, reason: reason})
+}
+
+func (c *Cache[K, V]) getLocked(k K, now time.Time) (e *cacheEntry[V], evicted []cacheEvicted[K, V]) {
+	e, ok := c.entries[k]
+	if !ok {
+		return nil, nil
+	}
+	if !e.expireAt.IsZero() && !now.Before(e.expireAt) {
+		c.policy.remove(k)
+		return nil, c.removeLocked(k, EvictExpired, nil)
+	}
+	return e, nil
+}
+
+func (c *Cache[K, V]) Get(k K) (v V, ok bool) {
+	now := c.now()
+	c.lock.Lock()
+	e, evicted := c.getLocked(k, now)
+	if e != nil {
+		c.stats.Hits++
+		c.policy.access(k)
+		v, ok = e.value, true
+	} else {
+		c.stats.Misses++
+	}
+	c.lock.Unlock()
+	c.notify(evicted)
+	return v, ok
+}
+
+// Peek returns the value without updating the statistics and the eviction order
+func (c *Cache[K, V]) Peek(k K) (v V, ok bool) {
+	now := c.now()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, exist := c.entries[k]; exist && (e.expireAt.IsZero() || now.Before(e.expireAt)) {
+		return e.value, true
+	}
+	return v, false
+}
+
+func (c *Cache[K, V]) Set(k K, v V) {
+	c.SetWithTTL(k, v, c.opts.TTL)
+}
+
+// SetWithTTL sets the entry with its own ttl, 0 means never expire. An entry heavier than MaxWeight
+// is evicted immediately.
+func (c *Cache[K, V]) SetWithTTL(k K, v V, ttl time.Duration) {
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = c.now().Add(ttl)
+	}
+	weight := int64(1)
+	if c.opts.Weigher != nil {
+		weight = c.opts.Weigher(k, v)
+	}
+
+	c.lock.Lock()
+	var evicted []cacheEvicted[K, V]
+	e, exist := c.entries[k]
+	switch {
+	case c.opts.MaxWeight > 0 && weight > c.opts.MaxWeight:
+		// never fits, and should not flush other entries
+		if exist {
+			c.policy.remove(k)
+			evicted = c.removeLocked(k, EvictReplaced, evicted)
+		}
+		c.stats.Evictions++
+		evicted = append(evicted, cacheEvicted[K, V]{k: k, v: v, reason: EvictCapacity})
+	case exist:
+		evicted = append(evicted, cacheEvicted[K, V]{k: k, v: e.value, reason: EvictReplaced})
+		c.weight += weight - e.weight
+		e.value, e.weight, e.expireAt = v, weight, expireAt
+		c.policy.access(k)
+	default:
+		// make room before adding, so that the new entry will not be evicted at once (by LFU)
+		for ok := true; ok && c.overflowed(1, weight); {
+			evicted, ok = c.evictLocked(evicted)
+		}
+		c.entries[k] = &cacheEntry[V]{value: v, weight: weight, expireAt: expireAt}
+		c.weight += weight
+		c.policy.add(k)
+	}
+	for ok := true; ok && c.overflowed(0, 0); {
+		evicted, ok = c.evictLocked(evicted)
+	}
+	c.lock.Unlock()
+	c.notify(evicted)
+}
+
+// overflowed reports whether the limits are exceeded after adding count entries with weight
+func (c *Cache[K, V]) overflowed(count int, weight int64) bool {
+	return (c.opts.Capacity > 0 && len(c.entries)+count > c.opts.Capacity) ||
+		(c.opts.MaxWeight > 0 && c.weight+weight > c.opts.MaxWeight)
+}
+
+// evictLocked evicts one entry by the policy, ok is false if nothing to evict
+func (c *Cache[K, V]) evictLocked(evicted []cacheEvicted[K, V]) ([]cacheEvicted[K, V], bool) {
+	victim, ok := c.policy.evict()
+	if !ok {
+		return evicted, false
+	}
+	return c.removeLocked(victim, EvictCapacity, evicted), true
+}
+
+func (c *Cache[K, V]) Delete(k K) bool {
+	c.lock.Lock()
+	_, exist := c.entries[k]
+	var evicted []cacheEvicted[K, V]
+	if exist {
+		c.policy.remove(k)
+		evicted = c.removeLocked(k, EvictDeleted, nil)
+	}
+	c.lock.Unlock()
+	c.notify(evicted)
+	return exist
+}
+
+// DeleteExpired removes all expired entries and returns the number of them
+func (c *Cache[K, V]) DeleteExpired() int {
+	now := c.now()
+	c.lock.Lock()
+	var evicted []cacheEvicted[K, V]
+	for k, e := range c.entries {
+		if !e.expireAt.IsZero() && !now.Before(e.expireAt) {
+			c.policy.remove(k)
+			evicted = c.removeLocked(k, EvictExpired, evicted)
+		}
+	}
+	c.lock.Unlock()
+	c.notify(evicted)
+	return len(evicted)
+}
+
+// Purge removes all entries, statistics are kept
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	var evicted []cacheEvicted[K, V]
+	for k := range c.entries {
+		c.policy.remove(k)
+		evicted = c.removeLocked(k, EvictDeleted, evicted)
+	}
+	c.lock.Unlock()
+	c.notify(evicted)
+}
+
+// Len number of entries, including the expired ones not removed yet
+func (c *Cache[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache[K, V]) Weight() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.weight
+}
+
+// Keys returns keys of unexpired entries in no particular order
+func (c *Cache[K, V]) Keys() []K {
+	now := c.now()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ks := make([]K, 0, len(c.entries))
+	for k, e := range c.entries {
+		if e.expireAt.IsZero() || now.Before(e.expireAt) {
+			ks = append(ks, k)
+		}
+	}
+	return ks
+}
+
+func (c *Cache[K, V]) Stats() CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.stats
+}
+
+// GetOrLoad returns the cached value, or loads and caches it by loader. Concurrent calls with the
+// same key share one loading, and failed loadings are not cached.
+func (c *Cache[K, V]) GetOrLoad(k K, loader func(k K) (V, error)) (V, error) {
+	if v, ok := c.Get(k); ok {
+		return v, nil
+	}
+	now := c.now()
+	c.lock.Lock()
+	if e, exist := c.entries[k]; exist && (e.expireAt.IsZero() || now.Before(e.expireAt)) {
+		// loaded by another caller just now
+		c.lock.Unlock()
+		return e.value, nil
+	}
+	if call, ok := c.calls[k]; ok {
+		c.lock.Unlock()
+		call.wg.Wait()
+		return call.v, call.err
+	}
+	call := new(cacheCall[V])
+	call.wg.Add(1)
+	c.calls[k] = call
+	c.stats.Loads++
+	c.lock.Unlock()
+
+	defer func() {
+		c.lock.Lock()
+		delete(c.calls, k)
+		if call.err != nil {
+			c.stats.LoadErrors++
+		}
+		c.lock.Unlock()
+		call.wg.Done()
+	}()
+	call.err = fmt.Errorf("tools: cache loader of %v panicked", k)
+	call.v, call.err = loader(k)
+	if call.err == nil {
+		c.Set(k, call.v)
+	}
+	return call.v, call.err
+}
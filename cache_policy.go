@@ -0,0 +1,238 @@
+package tools
+
+// cachePolicy decides which key to evict, all methods are called under the lock of the cache
+type cachePolicy[K comparable] interface {
+	// add a new key to the cache
+	add(k K)
+	// access an existing key
+	access(k K)
+	// remove a key deleted or expired
+	remove(k K)
+	// evict removes and returns the key should be evicted
+	evict() (K, bool)
+}
+
+// lruPolicy least recently used: front is the most recently used
+type lruPolicy[K comparable] struct {
+	l *List[K]
+	e map[K]*ListElement[K]
+}
+
+func newLRUPolicy[K comparable]() *lruPolicy[K] {
+	return &lruPolicy[K]{l: NewList[K](), e: make(map[K]*ListElement[K])}
+}
+
+func (p *lruPolicy[K]) add(k K) {
+	p.e[k] = p.l.PushFront(k)
+}
+
+func (p *lruPolicy[K]) access(k K) {
+	if elem, ok := p.e[k]; ok {
+		p.l.MoveToFront(elem)
+	}
+}
+
+func (p *lruPolicy[K]) remove(k K) {
+	if elem, ok := p.e[k]; ok {
+		p.l.Remove(elem)
+		delete(p.e, k)
+	}
+}
+
+func (p *lruPolicy[K]) evict() (k K, ok bool) {
+	elem := p.l.Back()
+	if elem == nil {
+		return k, false
+	}
+	p.remove(elem.Value)
+	return elem.Value, true
+}
+
+// lfuPolicy least frequently used, the least recently used one is evicted among keys with the same
+// frequency
+type lfuPolicy[K comparable] struct {
+	buckets map[int]*List[K] // frequency -> keys, front is the most recently used
+	freq    map[K]int
+	e       map[K]*ListElement[K]
+	minFreq int
+}
+
+func newLFUPolicy[K comparable]() *lfuPolicy[K] {
+	return &lfuPolicy[K]{buckets: make(map[int]*List[K]), freq: make(map[K]int), e: make(map[K]*ListElement[K])}
+}
+
+func (p *lfuPolicy[K]) push(k K, freq int) {
+	bucket, ok := p.buckets[freq]
+	if !ok {
+		bucket = NewList[K]()
+		p.buckets[freq] = bucket
+	}
+	p.freq[k] = freq
+	p.e[k] = bucket.PushFront(k)
+}
+
+// unlink removes k from its bucket and returns its frequency
+func (p *lfuPolicy[K]) unlink(k K) (int, bool) {
+	elem, ok := p.e[k]
+	if !ok {
+		return 0, false
+	}
+	freq := p.freq[k]
+	bucket := p.buckets[freq]
+	bucket.Remove(elem)
+	if bucket.Len() == 0 {
+		delete(p.buckets, freq)
+	}
+	delete(p.e, k)
+	delete(p.freq, k)
+	return freq, true
+}
+
+func (p *lfuPolicy[K]) add(k K) {
+	p.push(k, 1)
+	p.minFreq = 1
+}
+
+func (p *lfuPolicy[K]) access(k K) {
+	freq, ok := p.unlink(k)
+	if !ok {
+		return
+	}
+	if freq == p.minFreq && p.buckets[freq] == nil {
+		p.minFreq = freq + 1
+	}
+	p.push(k, freq+1)
+}
+
+func (p *lfuPolicy[K]) remove(k K) {
+	p.unlink(k)
+}
+
+func (p *lfuPolicy[K]) evict() (k K, ok bool) {
+	if len(p.e) == 0 {
+		return k, false
+	}
+	bucket := p.buckets[p.minFreq]
+	if bucket == nil {
+		// minFreq is stale after removing
+		p.minFreq = 0
+		for f := range p.buckets {
+			if p.minFreq == 0 || f < p.minFreq {
+				p.minFreq = f
+			}
+		}
+		bucket = p.buckets[p.minFreq]
+	}
+	k = bucket.Back().Value
+	p.unlink(k)
+	return k, true
+}
+
+const (
+	arcT1 = iota // recent, seen once
+	arcT2        // frequent, seen at least twice
+	arcB1        // ghosts evicted from t1
+	arcB2        // ghosts evicted from t2
+)
+
+type arcElement[K any] struct {
+	elem  *ListElement[K]
+	which int
+}
+
+// arcPolicy Adaptive Replacement Cache: balances between recency (t1) and frequency (t2) by the
+// target size p of t1, which is adapted with the hits on the ghost lists b1 and b2.
+type arcPolicy[K comparable] struct {
+	capacity int // 0 for the number of resident keys
+	p        int
+	lists    [4]*List[K]
+	e        map[K]arcElement[K]
+}
+
+func newARCPolicy[K comparable](capacity int) *arcPolicy[K] {
+	p := &arcPolicy[K]{capacity: capacity, e: make(map[K]arcElement[K])}
+	for i := range p.lists {
+		p.lists[i] = NewList[K]()
+	}
+	return p
+}
+
+func (p *arcPolicy[K]) c() int {
+	if p.capacity > 0 {
+		return p.capacity
+	}
+	return max(1, p.lists[arcT1].Len()+p.lists[arcT2].Len())
+}
+
+func (p *arcPolicy[K]) unlink(k K) (int, bool) {
+	ae, ok := p.e[k]
+	if !ok {
+		return 0, false
+	}
+	p.lists[ae.which].Remove(ae.elem)
+	delete(p.e, k)
+	return ae.which, true
+}
+
+func (p *arcPolicy[K]) push(k K, which int) {
+	p.e[k] = arcElement[K]{elem: p.lists[which].PushFront(k), which: which}
+}
+
+func (p *arcPolicy[K]) add(k K) {
+	b1, b2 := p.lists[arcB1].Len(), p.lists[arcB2].Len()
+	which, ghost := p.unlink(k)
+	switch {
+	case ghost && which == arcB1:
+		// t1 was too small
+		p.p = min(p.c(), p.p+max(1, b2/b1))
+		p.push(k, arcT2)
+	case ghost && which == arcB2:
+		// t2 was too small
+		p.p = max(0, p.p-max(1, b1/b2))
+		p.push(k, arcT2)
+	default:
+		p.push(k, arcT1)
+	}
+	p.trimGhosts()
+}
+
+func (p *arcPolicy[K]) trimGhosts() {
+	c := p.c()
+	for p.lists[arcB1].Len() > 0 && p.lists[arcT1].Len()+p.lists[arcB1].Len() > c {
+		p.unlink(p.lists[arcB1].Back().Value)
+	}
+	for p.lists[arcB2].Len() > 0 && len(p.e) > 2*c {
+		p.unlink(p.lists[arcB2].Back().Value)
+	}
+}
+
+func (p *arcPolicy[K]) access(k K) {
+	if ae, ok := p.e[k]; ok && (ae.which == arcT1 || ae.which == arcT2) {
+		p.unlink(k)
+		p.push(k, arcT2)
+	}
+}
+
+func (p *arcPolicy[K]) remove(k K) {
+	if ae, ok := p.e[k]; ok && (ae.which == arcT1 || ae.which == arcT2) {
+		p.unlink(k)
+	}
+}
+
+func (p *arcPolicy[K]) evict() (k K, ok bool) {
+	t1, t2 := p.lists[arcT1], p.lists[arcT2]
+	var from, to int
+	switch {
+	case t1.Len() > 0 && (t1.Len() > p.p || t2.Len() == 0):
+		from, to = arcT1, arcB1
+	case t2.Len() > 0:
+		from, to = arcT2, arcB2
+	default:
+		return k, false
+	}
+	k = p.lists[from].Back().Value
+	p.unlink(k)
+	p.push(k, to)
+	p.trimGhosts()
+	return k, true
+}
@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_LRU(t *testing.T) {
+	var evicted []string
+	c := NewCache(CacheOptions[string, int]{
+		Capacity: 3,
+		OnEvict: func(k string, v int, reason EvictReason) {
+			evicted = append(evicted, k+":"+reason.String())
+		},
+	})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.Get("a")
+	c.Set("d", 4) // evicts b
+	c.Set("c", 30)
+	c.Set("e", 5) // evicts a
+	if !slices.Equal(evicted, []string{"b:capacity", "c:replaced", "a:capacity"}) {
+		t.Fatalf("evicted: %v", evicted)
+	}
+	keys := c.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"c", "d", "e"}) {
+		t.Fatalf("keys: %v", keys)
+	}
+	if v, ok := c.Get("c"); !ok || v != 30 {
+		t.Fatalf("get c: %d %t", v, ok)
+	}
+	c.Get("x")
+	if s := c.Stats(); s.Hits != 2 || s.Misses != 1 || s.Evictions != 2 {
+		t.Fatalf("stats: %s", s)
+	}
+	if !c.Delete("c") || c.Delete("c") || c.Len() != 2 {
+		t.Fatal("delete failed")
+	}
+	c.Purge()
+	if c.Len() != 0 || len(evicted) != 6 {
+		t.Fatalf("purge: %d %v", c.Len(), evicted)
+	}
+}
+
+func TestCache_LFU(t *testing.T) {
+	c := NewCache(CacheOptions[int, int]{Policy: CacheLFU, Capacity: 3})
+	for i := 1; i <= 3; i++ {
+		c.Set(i, i)
+	}
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	c.Set(4, 4) // evicts 3 (freq 1)
+	c.Get(4)
+	c.Get(4)
+	c.Get(4)
+	c.Set(5, 5) // evicts 2 (freq 2)
+	c.Delete(5)
+	c.Set(6, 6)
+	c.Set(7, 7) // evicts 6, the least recently used with freq 1
+	keys := c.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []int{1, 4, 7}) {
+		t.Fatalf("keys: %v", keys)
+	}
+}
+
+func TestCache_ARC(t *testing.T) {
+	c := NewCache(CacheOptions[int, int]{Policy: CacheARC, Capacity: 4})
+	// hot keys accessed twice go to t2
+	for _, k := range []int{1, 2} {
+		c.Set(k, k)
+		c.Get(k)
+	}
+	// a scan of one-time keys should not flush the hot keys
+	for k := 100; k < 120; k++ {
+		c.Set(k, k)
+	}
+	for _, k := range []int{1, 2} {
+		if _, ok := c.Get(k); !ok {
+			t.Fatalf("hot key %d evicted by scan", k)
+		}
+	}
+	if c.Len() != 4 {
+		t.Fatalf("len: %d", c.Len())
+	}
+	p := c.policy.(*arcPolicy[int])
+	if len(p.e) > 2*4 || p.lists[arcB1].Len()+p.lists[arcT1].Len() > 4 {
+		t.Fatalf("ghost lists too large: %d", len(p.e))
+	}
+	// hit on a ghost of t1 enlarges the target of t1
+	ghost := p.lists[arcB1].Front().Value
+	c.Set(ghost, ghost)
+	if p.p == 0 || p.e[ghost].which != arcT2 {
+		t.Fatalf("adapt: p=%d", p.p)
+	}
+}
+
+func TestCache_TTLAndWeight(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var expired []int
+	c := NewCache(CacheOptions[int, string]{
+		TTL:       time.Minute,
+		MaxWeight: 10,
+		Weigher:   func(k int, v string) int64 { return int64(len(v)) },
+		Now:       func() time.Time { return now },
+		OnEvict: func(k int, v string, reason EvictReason) {
+			if reason == EvictExpired {
+				expired = append(expired, k)
+			}
+		},
+	})
+	c.Set(1, "aaaa")
+	c.SetWithTTL(2, "bbbb", 0)
+	c.SetWithTTL(3, "cc", time.Hour)
+	if c.Weight() != 10 {
+		t.Fatalf("weight: %d", c.Weight())
+	}
+	c.Set(4, "dd") // evicts 1
+	if _, ok := c.Peek(1); ok || c.Weight() != 8 {
+		t.Fatalf("weight eviction: %d", c.Weight())
+	}
+	c.Set(5, "too large value") // heavier than MaxWeight
+	if _, ok := c.Peek(5); ok {
+		t.Fatal("entry heavier than max weight should be evicted")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get(4); ok {
+		t.Fatal("4 should be expired")
+	}
+	if _, ok := c.Get(3); !ok {
+		t.Fatal("3 should not be expired")
+	}
+	now = now.Add(time.Hour)
+	if n := c.DeleteExpired(); n != 1 || c.Len() != 1 {
+		t.Fatalf("delete expired: %d %d", n, c.Len())
+	}
+	if !slices.Equal(expired, []int{4, 3}) || c.Stats().Expirations != 2 {
+		t.Fatalf("expired: %v %s", expired, c.Stats())
+	}
+}
+
+func TestCache_BackgroundCleanup(t *testing.T) {
+	var count atomic.Int32
+	c := NewCache(CacheOptions[int, int]{
+		TTL:             time.Millisecond,
+		CleanupInterval: 5 * time.Millisecond,
+		OnEvict:         func(int, int, EvictReason) { count.Add(1) },
+	})
+	defer c.Close()
+	c.Set(1, 1)
+	deadline := time.Now().Add(time.Second)
+	for count.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if count.Load() != 1 || c.Len() != 0 {
+		t.Fatalf("background cleanup: %d %d", count.Load(), c.Len())
+	}
+	c.Close()
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c := NewLRUCache[string, int](10)
+	var loads atomic.Int32
+	start := make(chan struct{})
+	loader := func(k string) (int, error) {
+		loads.Add(1)
+		<-start
+		return len(k), nil
+	}
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.GetOrLoad("abc", loader)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(start)
+	wg.Wait()
+	if loads.Load() != 1 || slices.Max(results) != 3 || slices.Min(results) != 3 {
+		t.Fatalf("loads: %d results: %v", loads.Load(), results)
+	}
+
+	errLoad := errors.New("load failed")
+	if _, err := c.GetOrLoad("x", func(string) (int, error) { return 0, errLoad }); !errors.Is(err, errLoad) {
+		t.Fatalf("load error: %v", err)
+	}
+	if _, ok := c.Peek("x"); ok {
+		t.Fatal("failed loading should not be cached")
+	}
+	if s := c.Stats(); s.Loads != 2 || s.LoadErrors != 1 {
+		t.Fatalf("stats: %s", s)
+	}
+}
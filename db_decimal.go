@@ -0,0 +1,514 @@
+package tools
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrDivisionByZero = errors.New("tools: division by zero")
+	ErrInvalidDecimal = errors.New("tools: invalid decimal string")
+)
+
+// RoundingMode decides how to deal with the discarded digits when the scale of a Decimal is reduced.
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // round half away from zero: 1.25 -> 1.3, -1.25 -> -1.3
+	RoundHalfEven                     // banker's rounding: 1.25 -> 1.2, 1.35 -> 1.4
+	RoundHalfDown                     // round half toward zero: 1.25 -> 1.2, 1.26 -> 1.3
+	RoundDown                         // toward zero (truncate): 1.29 -> 1.2, -1.29 -> -1.2
+	RoundUp                           // away from zero: 1.21 -> 1.3, -1.21 -> -1.3
+	RoundCeiling                      // toward positive infinity: 1.21 -> 1.3, -1.29 -> -1.2
+	RoundFloor                        // toward negative infinity: 1.29 -> 1.2, -1.21 -> -1.3
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfUp:
+		return "HalfUp"
+	case RoundHalfEven:
+		return "HalfEven"
+	case RoundHalfDown:
+		return "HalfDown"
+	case RoundDown:
+		return "Down"
+	case RoundUp:
+		return "Up"
+	case RoundCeiling:
+		return "Ceiling"
+	case RoundFloor:
+		return "Floor"
+	default:
+		return "RoundingMode-" + strconv.Itoa(int(m))
+	}
+}
+
+// MaxDecimalScale limits the exponent and the scale of parsed decimals, so that untrusted input
+// like "1e-300000000" can't make a huge coefficient or string.
+const MaxDecimalScale = 1000
+
+// Decimal 任意精度的十进制数，值为 coef * 10^(-scale)，用于DECIMAL类型的字段（如金额）。
+// Decimal是不可变的，所有运算都返回新的值。零值为0。
+type Decimal struct {
+	coef  *big.Int // nil means 0
+	scale int32    // always >= 0
+}
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// NewDecimal returns coef * 10^(-scale), a negative scale multiplies coef by 10^(-scale)
+func NewDecimal(coef int64, scale int32) (Decimal, error) {
+	return NewDecimalFromBigInt(big.NewInt(coef), scale)
+}
+
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal{coef: big.NewInt(i)}
+}
+
+// NewDecimalFromBigInt returns coef * 10^(-scale), coef is copied. ErrInvalidDecimal is returned if
+// scale is beyond ±MaxDecimalScale.
+func NewDecimalFromBigInt(coef *big.Int, scale int32) (Decimal, error) {
+	if err := checkDecimalScale(scale); err != nil {
+		return Decimal{}, err
+	}
+	if coef == nil {
+		return Decimal{}, nil
+	}
+	c := new(big.Int).Set(coef)
+	if scale < 0 {
+		c.Mul(c, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{coef: c, scale: scale}, nil
+}
+
+func checkDecimalScale(scale int32) error {
+	if scale > MaxDecimalScale || scale < -MaxDecimalScale {
+		return fmt.Errorf("%w: scale %d out of range", ErrInvalidDecimal, scale)
+	}
+	return nil
+}
+
+// NewDecimalFromFloat converts f with the shortest decimal representation that round-trips to f,
+// so 0.1 becomes exactly 0.1 instead of 0.1000000000000000055511151231257827.
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("%w: %v", ErrInvalidDecimal, f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// ParseDecimal parses strings like "123", "-1.50", "+.5", "1.2e-3", "1E5". Trailing zeros of the
+// fraction are kept as part of the scale. Both the exponent and the resulting scale are limited to
+// ±MaxDecimalScale.
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return Decimal{}, ErrInvalidDecimal
+	}
+	var exp int64
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil || e > MaxDecimalScale || e < -MaxDecimalScale {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+		exp = e
+		str = str[:i]
+	}
+	neg := false
+	switch {
+	case strings.HasPrefix(str, "-"):
+		neg, str = true, str[1:]
+	case strings.HasPrefix(str, "+"):
+		str = str[1:]
+	}
+	intPart, fracPart, hasPoint := strings.Cut(str, ".")
+	if (intPart == "" && fracPart == "") || (hasPoint && strings.Contains(fracPart, ".")) {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	digits := intPart + fracPart
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+	}
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	if neg {
+		coef.Neg(coef)
+	}
+	scale := int64(len(fracPart)) - exp
+	if scale > MaxDecimalScale || scale < -MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("%w: exponent out of range %q", ErrInvalidDecimal, s)
+	}
+	return NewDecimalFromBigInt(coef, int32(scale))
+}
+
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (f F) Decimal() (Decimal, error) {
+	return NewDecimalFromFloat(float64(f))
+}
+
+func (d Decimal) bigCoef() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// Coefficient returns a copy of the coefficient
+func (d Decimal) Coefficient() *big.Int { return new(big.Int).Set(d.bigCoef()) }
+func (d Decimal) Scale() int32          { return d.scale }
+func (d Decimal) Sign() int             { return d.bigCoef().Sign() }
+func (d Decimal) IsZero() bool          { return d.Sign() == 0 }
+func (d Decimal) IsNegative() bool      { return d.Sign() < 0 }
+func (d Decimal) IsPositive() bool      { return d.Sign() > 0 }
+
+// IsInteger reports whether d has no fractional part
+func (d Decimal) IsInteger() bool {
+	if d.scale == 0 || d.IsZero() {
+		return true
+	}
+	return new(big.Int).Rem(d.bigCoef(), pow10(d.scale)).Sign() == 0
+}
+
+// rescale returns the coefficient of d at a larger scale, scale must be >= d.scale
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return d.bigCoef()
+	}
+	return new(big.Int).Mul(d.bigCoef(), pow10(scale-d.scale))
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.bigCoef()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.bigCoef()), scale: d.scale}
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	scale := max(d.scale, o.scale)
+	return Decimal{coef: new(big.Int).Add(d.rescale(scale), o.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	scale := max(d.scale, o.scale)
+	return Decimal{coef: new(big.Int).Sub(d.rescale(scale), o.rescale(scale)), scale: scale}
+}
+
+// Mul the scale of the result is the sum of both scales, no rounding happens. It panics with
+// ErrInvalidDecimal if the sum overflows int32.
+func (d Decimal) Mul(o Decimal) Decimal {
+	scale := int64(d.scale) + int64(o.scale)
+	if scale > math.MaxInt32 {
+		panic(fmt.Errorf("%w: scale %d out of range", ErrInvalidDecimal, scale))
+	}
+	return Decimal{coef: new(big.Int).Mul(d.bigCoef(), o.bigCoef()), scale: int32(scale)}
+}
+
+// Div returns d/o rounded to scale digits after the decimal point with mode, a negative scale means
+// 0. ErrInvalidDecimal is returned if scale is beyond ±MaxDecimalScale.
+func (d Decimal) Div(o Decimal, scale int32, mode RoundingMode) (Decimal, error) {
+	if err := checkDecimalScale(scale); err != nil {
+		return Decimal{}, err
+	}
+	if o.IsZero() {
+		return Decimal{}, ErrDivisionByZero
+	}
+	if scale < 0 {
+		scale = 0
+	}
+	// d/o*10^scale = dc * 10^(os-ds+scale) / oc
+	num, den := new(big.Int).Set(d.bigCoef()), new(big.Int).Set(o.bigCoef())
+	if e := int64(o.scale) - int64(d.scale) + int64(scale); e >= 0 {
+		num.Mul(num, pow10(int32(e)))
+	} else {
+		den.Mul(den, pow10(int32(-e)))
+	}
+	return Decimal{coef: roundQuo(num, den, mode), scale: scale}, nil
+}
+
+// MustDiv like Div but panics when o is zero or scale is out of range
+func (d Decimal) MustDiv(o Decimal, scale int32, mode RoundingMode) Decimal {
+	r, err := d.Div(o, scale, mode)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// roundQuo returns num/den rounded to integer with mode
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	neg := (num.Sign() < 0) != (den.Sign() < 0)
+	// cmpHalf compares the discarded fraction with 1/2
+	cmpHalf := new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).CmpAbs(den)
+	var away bool
+	switch mode {
+	case RoundHalfUp:
+		away = cmpHalf >= 0
+	case RoundHalfEven:
+		away = cmpHalf > 0 || (cmpHalf == 0 && q.Bit(0) == 1)
+	case RoundHalfDown:
+		away = cmpHalf > 0
+	case RoundDown:
+		away = false
+	case RoundUp:
+		away = true
+	case RoundCeiling:
+		away = !neg
+	case RoundFloor:
+		away = neg
+	}
+	if away {
+		if neg {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return q
+}
+
+// Round returns d rounded to scale digits after the decimal point with mode. If scale is larger than
+// the scale of d, trailing zeros are appended up to MaxDecimalScale.
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	// more trailing zeros don't change the value
+	scale = min(scale, max(MaxDecimalScale, d.scale))
+	if scale >= d.scale {
+		return Decimal{coef: d.rescale(scale), scale: scale}
+	}
+	return Decimal{coef: roundQuo(d.bigCoef(), pow10(d.scale-scale), mode), scale: scale}
+}
+
+// Truncate is Round with RoundDown
+func (d Decimal) Truncate(scale int32) Decimal {
+	return d.Round(scale, RoundDown)
+}
+
+// Normalize removes trailing zeros of the fraction, 1.500 -> 1.5
+func (d Decimal) Normalize() Decimal {
+	if d.IsZero() {
+		return Decimal{}
+	}
+	c, scale := new(big.Int).Set(d.bigCoef()), d.scale
+	r := new(big.Int)
+	for scale > 0 {
+		q, _ := new(big.Int).QuoRem(c, bigTen, r)
+		if r.Sign() != 0 {
+			break
+		}
+		c, scale = q, scale-1
+	}
+	return Decimal{coef: c, scale: scale}
+}
+
+// Cmp compares d and o and returns -1 if d < o, 0 if d == o, +1 if d > o. Scale is not taken into
+// account, 1.50 equals to 1.5
+func (d Decimal) Cmp(o Decimal) int {
+	scale := max(d.scale, o.scale)
+	return d.rescale(scale).Cmp(o.rescale(scale))
+}
+
+func (d Decimal) Compare(o Decimal) int { return d.Cmp(o) }
+func (d Decimal) Equal(o Decimal) bool  { return d.Cmp(o) == 0 }
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+// Int64 returns the integer part of d, flowed is true if it overflows int64
+func (d Decimal) Int64() (i int64, flowed bool) {
+	c := d.Truncate(0).bigCoef()
+	if !c.IsInt64() {
+		if c.Sign() < 0 {
+			return math.MinInt64, true
+		}
+		return math.MaxInt64, true
+	}
+	return c.Int64(), false
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) F() F { return F(d.Float64()) }
+
+// String returns the plain decimal representation with exactly Scale() fraction digits.
+func (d Decimal) String() string {
+	c := d.bigCoef()
+	if d.scale == 0 {
+		return c.String()
+	}
+	digits := new(big.Int).Abs(c).String()
+	if n := int(d.scale) + 1 - len(digits); n > 0 {
+		digits = strings.Repeat("0", n) + digits
+	}
+	point := len(digits) - int(d.scale)
+	s := digits[:point] + "." + digits[point:]
+	if c.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// StringFixed returns the representation rounded (RoundHalfUp) or zero padded to places fraction
+// digits, the padding is limited like Round
+func (d Decimal) StringFixed(places int32) string {
+	return d.Round(places, RoundHalfUp).String()
+}
+
+// Format implements fmt.Formatter, %f/%F honours the precision (e.g. %.2f), %v/%s use String().
+// Width and the '-' flag are supported.
+func (d Decimal) Format(f fmt.State, c rune) {
+	var s string
+	switch c {
+	case 'f', 'F':
+		if p, ok := f.Precision(); ok {
+			s = d.StringFixed(int32(p))
+		} else {
+			s = d.String()
+		}
+	case 'v', 's':
+		s = d.String()
+	case 'q':
+		s = strconv.Quote(d.String())
+	default:
+		_, _ = fmt.Fprintf(f, "%%!%c(tools.Decimal=%s)", c, d.String())
+		return
+	}
+	if w, ok := f.Width(); ok && len(s) < w {
+		pad := strings.Repeat(" ", w-len(s))
+		if f.Flag('-') {
+			s = s + pad
+		} else {
+			s = pad + s
+		}
+	}
+	_, _ = fmt.Fprint(f, s)
+}
+
+// MarshalJSON marshals d as a JSON string to keep the precision for JavaScript clients, use
+// DecimalNumber for a JSON number. Both forms are accepted when unmarshalling.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return ErrNilSource
+	}
+	str := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+	}
+	v, err := ParseDecimal(str)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d *Decimal) Scan(value any) error {
+	if d == nil {
+		return ErrNilValue
+	}
+	var err error
+	switch v := value.(type) {
+	case nil:
+		return ErrNilSource
+	case []byte:
+		*d, err = ParseDecimal(string(v))
+	case string:
+		*d, err = ParseDecimal(v)
+	case int64:
+		*d = NewDecimalFromInt(v)
+	case float64:
+		*d, err = NewDecimalFromFloat(v)
+	default:
+		return fmt.Errorf("tools: Decimal scan source was not []byte, string, int64 or float64 but %T", value)
+	}
+	return err
+}
+
+// Value DECIMAL columns accept strings without losing precision
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+type NullDecimal = Null[Decimal]
+
+// DecimalNumber a Decimal marshalled as a JSON number
+type DecimalNumber Decimal
+
+func (n DecimalNumber) ToDecimal() Decimal { return Decimal(n) }
+func (n DecimalNumber) String() string     { return Decimal(n).String() }
+
+func (n DecimalNumber) MarshalJSON() ([]byte, error) {
+	return []byte(Decimal(n).String()), nil
+}
+
+func (n *DecimalNumber) UnmarshalJSON(data []byte) error {
+	return (*Decimal)(n).UnmarshalJSON(data)
+}
+
+func (n *DecimalNumber) Scan(value any) error {
+	return (*Decimal)(n).Scan(value)
+}
+
+func (n DecimalNumber) Value() (driver.Value, error) {
+	return Decimal(n).Value()
+}
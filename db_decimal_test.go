@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   bool
+	}{
+		{"0", "0", false},
+		{"123", "123", false},
+		{"-1.50", "-1.50", false},
+		{"+.5", "0.5", false},
+		{"5.", "5", false},
+		{"1.2e-3", "0.0012", false},
+		{"1E5", "100000", false},
+		{"-0.007", "-0.007", false},
+		{"", "", true},
+		{".", "", true},
+		{"1.2.3", "", true},
+		{"1a", "", true},
+		{"--1", "", true},
+		{"1e1000", "1" + strings.Repeat("0", 1000), false},
+		{"1e30000000", "", true},
+		{"1e-300000000", "", true},
+		{"1e99999999999", "", true},
+		{"0." + strings.Repeat("1", 1000) + "e-1", "", true},
+	}
+	for _, test := range tests {
+		d, err := ParseDecimal(test.input)
+		if (err != nil) != test.err {
+			t.Fatalf("input:%q error:%v, want error:%t", test.input, err, test.err)
+		}
+		if err == nil && d.String() != test.want {
+			t.Fatalf("input:%q got %s, want %s", test.input, d, test.want)
+		}
+	}
+}
+
+func TestDecimal_ScaleLimit(t *testing.T) {
+	var d Decimal
+	for _, input := range []string{`"1e30000000"`, `1e-300000000`} {
+		if err := d.UnmarshalJSON([]byte(input)); !errors.Is(err, ErrInvalidDecimal) {
+			t.Fatalf("unmarshal %s: %v", input, err)
+		}
+	}
+	if err := d.Scan("1e-1001"); !errors.Is(err, ErrInvalidDecimal) {
+		t.Fatalf("scan: %v", err)
+	}
+	if _, err := NewDecimal(1, -MaxDecimalScale-1); !errors.Is(err, ErrInvalidDecimal) {
+		t.Fatalf("NewDecimal: %v", err)
+	}
+	one := NewDecimalFromInt(1)
+	if _, err := one.Div(NewDecimalFromInt(3), math.MaxInt32, RoundDown); !errors.Is(err, ErrInvalidDecimal) {
+		t.Fatalf("div: %v", err)
+	}
+	if r := one.Round(math.MaxInt32, RoundDown); r.Scale() != MaxDecimalScale {
+		t.Fatalf("round: %d", r.Scale())
+	}
+	if s := one.StringFixed(math.MaxInt32); len(s) != MaxDecimalScale+2 {
+		t.Fatalf("string fixed: %d", len(s))
+	}
+}
+
+func TestDecimal_Round(t *testing.T) {
+	tests := []struct {
+		input string
+		mode  RoundingMode
+		scale int32
+		want  string
+	}{
+		{"1.25", RoundHalfUp, 1, "1.3"},
+		{"-1.25", RoundHalfUp, 1, "-1.3"},
+		{"1.25", RoundHalfEven, 1, "1.2"},
+		{"1.35", RoundHalfEven, 1, "1.4"},
+		{"-1.25", RoundHalfEven, 1, "-1.2"},
+		{"1.251", RoundHalfEven, 1, "1.3"},
+		{"1.25", RoundHalfDown, 1, "1.2"},
+		{"1.26", RoundHalfDown, 1, "1.3"},
+		{"1.29", RoundDown, 1, "1.2"},
+		{"-1.29", RoundDown, 1, "-1.2"},
+		{"1.21", RoundUp, 1, "1.3"},
+		{"-1.21", RoundUp, 1, "-1.3"},
+		{"-1.29", RoundCeiling, 1, "-1.2"},
+		{"1.21", RoundCeiling, 1, "1.3"},
+		{"-1.21", RoundFloor, 1, "-1.3"},
+		{"1.29", RoundFloor, 1, "1.2"},
+		{"1.2", RoundHalfUp, 3, "1.200"},
+		{"0.5", RoundHalfEven, 0, "0"},
+		{"2.5", RoundHalfEven, 0, "2"},
+		{"-0.5", RoundHalfUp, 0, "-1"},
+	}
+	for _, test := range tests {
+		got := MustParseDecimal(test.input).Round(test.scale, test.mode).String()
+		if got != test.want {
+			t.Fatalf("%s.Round(%d, %s) = %s, want %s", test.input, test.scale, test.mode, got, test.want)
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a, b := MustParseDecimal("10.25"), MustParseDecimal("-3.1")
+	if s := a.Add(b).String(); s != "7.15" {
+		t.Fatalf("add: %s", s)
+	}
+	if s := a.Sub(b).String(); s != "13.35" {
+		t.Fatalf("sub: %s", s)
+	}
+	if s := a.Mul(b).String(); s != "-31.775" {
+		t.Fatalf("mul: %s", s)
+	}
+	if s := a.MustDiv(b, 4, RoundHalfUp).String(); s != "-3.3065" {
+		t.Fatalf("div: %s", s)
+	}
+	if s := NewDecimalFromInt(1).MustDiv(NewDecimalFromInt(3), 2, RoundHalfUp).String(); s != "0.33" {
+		t.Fatalf("div: %s", s)
+	}
+	if _, err := a.Div(Decimal{}, 2, RoundHalfUp); err != ErrDivisionByZero {
+		t.Fatalf("div by zero: %v", err)
+	}
+	if !MustParseDecimal("1.50").Equal(MustParseDecimal("1.5")) || MustParseDecimal("1.5").Cmp(MustParseDecimal("1.49")) != 1 {
+		t.Fatal("compare failed")
+	}
+	if s := MustParseDecimal("1.500").Normalize().String(); s != "1.5" {
+		t.Fatalf("normalize: %s", s)
+	}
+	if d, _ := NewDecimalFromFloat(0.1); d.String() != "0.1" {
+		t.Fatalf("from float: %s", d)
+	}
+	d25, _ := NewDecimal(25, 1)
+	if s := fmt.Sprintf("%.2f|%8v|%-6s|", MustParseDecimal("3.14159"), MustParseDecimal("-1.5"), d25); s != "3.14|    -1.5|2.5   |" {
+		t.Fatalf("format: %q", s)
+	}
+}
+
+func TestDecimal_SQLAndJSON(t *testing.T) {
+	var d Decimal
+	if err := d.Scan([]byte("12345678901234567890.12")); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := d.Value(); v != "12345678901234567890.12" {
+		t.Fatalf("value: %v", v)
+	}
+	type price struct {
+		P Decimal     `json:"p"`
+		N NullDecimal `json:"n"`
+		M NullDecimal `json:"m"`
+	}
+	p := price{P: d, N: NewNull(MustParseDecimal("0.10"))}
+	s, err := JsonString(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"p":"12345678901234567890.12","n":"0.10","m":null}`; s != want {
+		t.Fatalf("got %s, want %s", s, want)
+	}
+	var pp price
+	if err = json.Unmarshal([]byte(`{"p":1.5,"n":"0.10","m":null}`), &pp); err != nil {
+		t.Fatal(err)
+	}
+	if pp.P.String() != "1.5" || !pp.N.Equal(p.N) || !pp.M.IsNull() {
+		t.Fatalf("unmarshal: %+v", pp)
+	}
+	var num struct {
+		N DecimalNumber `json:"n"`
+	}
+	if err = json.Unmarshal([]byte(`{"n":"12345678901234567890.12"}`), &num); err != nil || !num.N.ToDecimal().Equal(d) {
+		t.Fatalf("number unmarshal: %v %v", num, err)
+	}
+	if bs, err := json.Marshal(num); err != nil || string(bs) != `{"n":12345678901234567890.12}` {
+		t.Fatalf("number marshal: %s %v", bs, err)
+	}
+	var nd NullDecimal
+	if err = nd.Scan("9.99"); err != nil || nd.String() != "9.99" {
+		t.Fatalf("null scan: %v %v", nd, err)
+	}
+	if v, err := nd.Value(); err != nil || v != "9.99" {
+		t.Fatalf("null value: %v %v", v, err)
+	}
+}
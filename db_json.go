@@ -6,7 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
+
+	"github.com/stephenfire/go-tools/log"
 )
 
 type JSONBuilder struct{}
@@ -218,6 +222,20 @@ func (n NotNullJSON) ToJSON() JSON {
 	return JSON(n)
 }
 
+// Redact returns a copy of j with sensitive members and values masked, log.DefaultRedactor is used
+// if redactor not specified. A null JSON stays null.
+func (j JSON) Redact(redactor ...*log.Redactor) (JSON, error) {
+	if j.IsNull() {
+		return nil, nil
+	}
+	return VariadicParam(redactor, log.DefaultRedactor).RedactJSON(j)
+}
+
+func (n NotNullJSON) Redact(redactor ...*log.Redactor) (NotNullJSON, error) {
+	bs, err := VariadicParam(redactor, log.DefaultRedactor).RedactJSON(n.Bytes())
+	return NotNullJSON(bs), err
+}
+
 type JSONArray[T comparable] []T
 
 func NewJSONArray[T comparable](data JSON) (ja JSONArray[T], err error) {
@@ -270,3 +288,296 @@ func (ja JSONArray[T]) MarshalJSON() ([]byte, error) {
 func (ja JSONArray[T]) Equal(jt JSONArray[T]) bool {
 	return (KS[T])(ja).Equal((KS[T])(jt))
 }
+
+func (ja *JSONArray[T]) UnmarshalJSON(data []byte) error {
+	var arr []T
+	if err := json.Unmarshal(data, &arr); err != nil {
+		return err
+	}
+	*ja = arr
+	return nil
+}
+
+func (ja *JSONArray[T]) Scan(value any) error {
+	bs, err := jsonScanBytes(value, "JSONArray")
+	if err != nil {
+		return err
+	}
+	if bs == nil {
+		*ja = nil
+		return nil
+	}
+	return ja.UnmarshalJSON(bs)
+}
+
+func (ja JSONArray[T]) Value() (driver.Value, error) {
+	if ja == nil {
+		return nil, nil
+	}
+	return ja.MarshalJSON()
+}
+
+// jsonScanBytes returns nil if value is nil
+func jsonScanBytes(value any, typeName string) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("tools: %s scan source was not []byte or string but %T", typeName, value)
+	}
+}
+
+// JSONValidator is called after the value is decoded and before it is encoded by JSONOf
+type JSONValidator interface {
+	Validate() error
+}
+
+var _jsonOfValidators sync.Map // reflect.Type -> []func(JSON) error
+
+// RegisterJSONValidator adds validators of the raw JSON of JSONOf[T], they are called before T is
+// decoded and after T is encoded, such as a JSON Schema validator.
+func RegisterJSONValidator[T any](validators ...func(JSON) error) {
+	typ := reflect.TypeFor[T]()
+	for {
+		old, loaded := _jsonOfValidators.Load(typ)
+		var fns []func(JSON) error
+		if loaded {
+			fns = old.([]func(JSON) error)
+		}
+		fns = append(CopySlice(fns), validators...)
+		if !loaded {
+			if _, loaded = _jsonOfValidators.LoadOrStore(typ, fns); !loaded {
+				return
+			}
+		} else if _jsonOfValidators.CompareAndSwap(typ, old, fns) {
+			return
+		}
+	}
+}
+
+// JSONOf a JSON/JSONB column (or field) which is decoded into T, replacing the hand-written wrappers
+// around JSON. SQL NULL is not accepted, use NullJSONOf for nullable columns.
+// Validation: raw JSON is checked by validators registered by RegisterJSONValidator[T], and then T is
+// checked if it (or *T) implements JSONValidator.
+type JSONOf[T any] struct {
+	V T
+}
+
+type NullJSONOf[T any] = Null[JSONOf[T]]
+
+func NewJSONOf[T any](v T) JSONOf[T] {
+	return JSONOf[T]{V: v}
+}
+
+// ParseJSONOf decodes and validates data
+func ParseJSONOf[T any](data []byte) (JSONOf[T], error) {
+	var j JSONOf[T]
+	err := j.UnmarshalJSON(data)
+	return j, err
+}
+
+func (j JSONOf[T]) Get() T { return j.V }
+
+func (j JSONOf[T]) validateRaw(raw JSON) error {
+	if fns, ok := _jsonOfValidators.Load(reflect.TypeFor[T]()); ok {
+		for _, fn := range fns.([]func(JSON) error) {
+			if err := fn(raw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (j *JSONOf[T]) validateValue() error {
+	if v, ok := any(j.V).(JSONValidator); ok {
+		return v.Validate()
+	}
+	if v, ok := any(&j.V).(JSONValidator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// Validate checks V by its JSONValidator implementation and the registered validators
+func (j JSONOf[T]) Validate() error {
+	_, err := j.JSON()
+	return err
+}
+
+// JSON returns the compact JSON of V after validation
+func (j JSONOf[T]) JSON() (JSON, error) {
+	if err := j.validateValue(); err != nil {
+		return nil, err
+	}
+	raw, err := JSONBuilder{}.FromObj(j.V)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		raw = JSON("null")
+	}
+	if err = j.validateRaw(raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (j JSONOf[T]) MarshalJSON() ([]byte, error) {
+	return j.JSON()
+}
+
+func (j *JSONOf[T]) UnmarshalJSON(data []byte) error {
+	if err := j.validateRaw(data); err != nil {
+		return err
+	}
+	var jj JSONOf[T]
+	if err := json.Unmarshal(data, &jj.V); err != nil {
+		return err
+	}
+	if err := jj.validateValue(); err != nil {
+		return err
+	}
+	*j = jj
+	return nil
+}
+
+func (j *JSONOf[T]) Scan(value any) error {
+	bs, err := jsonScanBytes(value, "JSONOf")
+	if err != nil {
+		return err
+	}
+	if bs == nil {
+		return ErrNilSource
+	}
+	return j.UnmarshalJSON(bs)
+}
+
+func (j JSONOf[T]) Value() (driver.Value, error) {
+	raw, err := j.JSON()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(raw), nil
+}
+
+// JSONObject a JSON object keeps the order of its fields, which can be used as a DB column. The
+// zero value is SQL NULL and JSON null, and becomes an empty object when a field is set.
+type JSONObject struct {
+	*OrderMap[string, JSON]
+}
+
+func NewJSONObject() JSONObject {
+	return JSONObject{OrderMap: NewOrderMap[string, JSON]()}
+}
+
+func ParseJSONObject(data []byte) (JSONObject, error) {
+	var o JSONObject
+	if err := o.UnmarshalJSON(data); err != nil {
+		return JSONObject{}, err
+	}
+	return o, nil
+}
+
+func (o JSONObject) IsNull() bool {
+	return o.OrderMap == nil
+}
+
+// orderMap returns the fields, a zero JSONObject becomes an empty object
+func (o *JSONObject) orderMap() *OrderMap[string, JSON] {
+	if o.OrderMap == nil {
+		o.OrderMap = NewOrderMap[string, JSON]()
+	}
+	return o.OrderMap
+}
+
+// Set sets the field k, the zero value becomes an empty object first
+func (o *JSONObject) Set(k string, v JSON) *OrderMap[string, JSON] {
+	return o.orderMap().Set(k, v)
+}
+
+// Put sets the field k if it not exists, the zero value becomes an empty object first
+func (o *JSONObject) Put(k string, v JSON) *OrderMap[string, JSON] {
+	return o.orderMap().Put(k, v)
+}
+
+// SetValue sets the field k with the JSON encoding of v
+func (o *JSONObject) SetValue(k string, v any) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	o.Set(k, bs)
+	return nil
+}
+
+// GetValue decodes the field k into v, returns false if k not exists
+func (o JSONObject) GetValue(k string, v any) (bool, error) {
+	if o.OrderMap == nil {
+		return false, nil
+	}
+	raw, exist := o.Get(k)
+	if !exist {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+func (o JSONObject) Clone() JSONObject {
+	if o.OrderMap == nil {
+		return JSONObject{}
+	}
+	ret := NewJSONObject()
+	for k, v := range o.All() {
+		ret.Put(k, v.Clone())
+	}
+	return ret
+}
+
+func (o JSONObject) ToJSON() (JSON, error) {
+	return o.MarshalJSON()
+}
+
+func (o JSONObject) MarshalJSON() ([]byte, error) {
+	return o.OrderMap.MarshalJSON()
+}
+
+func (o *JSONObject) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.OrderMap = nil
+		return nil
+	}
+	om := NewOrderMap[string, JSON]()
+	if err := om.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	o.OrderMap = om
+	return nil
+}
+
+func (o *JSONObject) Scan(value any) error {
+	bs, err := jsonScanBytes(value, "JSONObject")
+	if err != nil {
+		return err
+	}
+	if bs == nil {
+		o.OrderMap = nil
+		return nil
+	}
+	return o.UnmarshalJSON(bs)
+}
+
+func (o JSONObject) Value() (driver.Value, error) {
+	if o.OrderMap == nil {
+		return nil, nil
+	}
+	bs, err := o.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stephenfire/go-tools/log"
+)
+
+type testJSONConfig struct {
+	Name  string     `json:"name"`
+	Ports []int      `json:"ports,omitempty"`
+	Tags  KS[string] `json:"tags,omitempty"`
+}
+
+func (c testJSONConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestJSONOf(t *testing.T) {
+	var c JSONOf[testJSONConfig]
+	if err := c.Scan([]byte(` {"name":"a", "ports":[80,443]} `)); err != nil {
+		t.Fatal(err)
+	}
+	if c.V.Name != "a" || len(c.V.Ports) != 2 {
+		t.Fatalf("scan: %+v", c)
+	}
+	v, err := c.Value()
+	if err != nil || string(v.([]byte)) != `{"name":"a","ports":[80,443]}` {
+		t.Fatalf("value: %s %v", v, err)
+	}
+	if err = c.Scan(`{"ports":[1]}`); err == nil || c.V.Name != "a" {
+		t.Fatalf("validation failed: %v %+v", err, c)
+	}
+	if err = c.Scan(nil); !errors.Is(err, ErrNilSource) {
+		t.Fatalf("scan nil: %v", err)
+	}
+	if _, err = NewJSONOf(testJSONConfig{}).Value(); err == nil {
+		t.Fatal("value should be validated")
+	}
+
+	var n NullJSONOf[map[string]int]
+	if err = n.Scan(nil); err != nil || !n.IsNull() {
+		t.Fatalf("null scan: %v %v", n, err)
+	}
+	if err = n.Scan([]byte(`{"a":1}`)); err != nil || n.V.V["a"] != 1 {
+		t.Fatalf("null scan: %v %v", n, err)
+	}
+	if v, err = n.Value(); err != nil || string(v.([]byte)) != `{"a":1}` {
+		t.Fatalf("null value: %s %v", v, err)
+	}
+
+	type row struct {
+		C JSONOf[testJSONConfig] `json:"c"`
+		N NullJSONOf[[]int]      `json:"n"`
+	}
+	s, err := JsonString(row{C: NewJSONOf(testJSONConfig{Name: "b"})})
+	if err != nil || s != `{"c":{"name":"b"},"n":null}` {
+		t.Fatalf("marshal: %s %v", s, err)
+	}
+}
+
+func TestRegisterJSONValidator(t *testing.T) {
+	type limited []string
+	RegisterJSONValidator[limited](func(raw JSON) error {
+		if len(raw) > 12 {
+			return errors.New("too long")
+		}
+		return nil
+	})
+	if _, err := ParseJSONOf[limited]([]byte(`["a","b"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseJSONOf[limited]([]byte(`["abc","def","ghi"]`)); err == nil || !strings.Contains(err.Error(), "too long") {
+		t.Fatalf("raw validator on decode: %v", err)
+	}
+	if _, err := NewJSONOf(limited{"abc", "def", "ghi"}).Value(); err == nil {
+		t.Fatal("raw validator on encode")
+	}
+}
+
+func TestJSONArray_Scan(t *testing.T) {
+	var ja JSONArray[int64]
+	if err := ja.Scan([]byte(`[3,1,2]`)); err != nil || !ja.Equal(JSONArray[int64]{3, 1, 2}) {
+		t.Fatalf("scan: %v %v", ja, err)
+	}
+	if v, err := ja.Value(); err != nil || string(v.([]byte)) != `[3,1,2]` {
+		t.Fatalf("value: %s %v", v, err)
+	}
+	if err := ja.Scan(nil); err != nil || ja != nil {
+		t.Fatalf("scan nil: %v %v", ja, err)
+	}
+	if v, err := ja.Value(); err != nil || v != nil {
+		t.Fatalf("nil value: %v %v", v, err)
+	}
+}
+
+func TestJSON_Redact(t *testing.T) {
+	j := JSON(`{"user":{"phone":"13812345678","remark":"call 13900001111"},"id_card":null}`)
+	got, err := j.Redact()
+	if err != nil || string(got) != `{"user":{"phone":"******","remark":"call 139****1111"},"id_card":null}` {
+		t.Fatalf("redact: %s %v", got, err)
+	}
+	if got, err = JSON(nil).Redact(); err != nil || got != nil {
+		t.Fatalf("null: %s %v", got, err)
+	}
+	type login struct {
+		Name  string `json:"name"`
+		Token string `json:"token"`
+	}
+	s, err := JsonString(login{Name: "a@b.com", Token: "x"}, JsonOptions{Redactor: log.DefaultRedactor, SortKeys: true})
+	if err != nil || s != `{"name":"a***@b.com","token":"******"}` {
+		t.Fatalf("json string: %s %v", s, err)
+	}
+}
+
+func TestJSONObject(t *testing.T) {
+	data := `{"zeta": 1, "alpha": {"b": 2, "a": 1}, "mid": [3, 1], "n": null, "zeta": "dup"}`
+	o, err := ParseJSONObject([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"zeta":"dup","alpha":{"b":2,"a":1},"mid":[3,1],"n":null}`
+	if v, err := o.Value(); err != nil || string(v.([]byte)) != want {
+		t.Fatalf("value: %s %v", v, err)
+	}
+	var name string
+	if ok, err := o.GetValue("zeta", &name); !ok || err != nil || name != "dup" {
+		t.Fatalf("get: %t %v %s", ok, err, name)
+	}
+	if err = o.SetValue("added", testJSONConfig{Name: "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var zero, zeroPut JSONObject
+	if err = zero.SetValue("a", 1); err != nil || zero.IsNull() {
+		t.Fatalf("set value on zero: %v", err)
+	}
+	zeroPut.Put("b", JSON("2"))
+	if bs, _ := zero.MarshalJSON(); string(bs) != `{"a":1}` || zeroPut.Len() != 1 {
+		t.Fatalf("zero value: %s %d", bs, zeroPut.Len())
+	}
+
+	var scanned JSONObject
+	if err = scanned.Scan(`{"b":1,"a":2}`); err != nil || scanned.IsNull() {
+		t.Fatal(err)
+	}
+	if got := slices.Collect(scanned.Keys()); !slices.Equal(got, []string{"b", "a"}) {
+		t.Fatalf("keys: %v", got)
+	}
+	if err = scanned.Scan(nil); err != nil || !scanned.IsNull() {
+		t.Fatal("scan nil should be null")
+	}
+	if v, err := scanned.Value(); v != nil || err != nil {
+		t.Fatalf("null value: %v %v", v, err)
+	}
+	if err = scanned.Scan("[1]"); err == nil {
+		t.Fatal("array should fail")
+	}
+
+	s := struct {
+		Obj  JSONObject                `json:"obj"`
+		Null JSONObject                `json:"null"`
+		Map  *OrderMap[string, string] `json:"map"`
+	}{}
+	in := `{"obj":{"y":1,"x":{"q":1}},"null":null,"map":{"k2":"v2","k1":"v1"}}`
+	if err = json.Unmarshal([]byte(in), &s); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(s)
+	if err != nil || string(out) != in {
+		t.Fatalf("round trip: %s %v", out, err)
+	}
+	c := s.Obj.Clone()
+	c.Set("y", JSON("2"))
+	if raw, _ := s.Obj.Get("y"); string(raw) != "1" {
+		t.Fatal("clone should be independent")
+	}
+
+	im := NewOrderMap[uint8, int]()
+	if err = json.Unmarshal([]byte(`{"3":1,"1":2}`), im); err != nil || !slices.Equal(slices.Collect(im.Keys()), []uint8{3, 1}) {
+		t.Fatalf("int keys: %v", err)
+	}
+	if err = json.Unmarshal([]byte(`{"300":1}`), im); err == nil {
+		t.Fatal("overflowed key should fail")
+	}
+}
@@ -0,0 +1,490 @@
+package tools
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("tools: currency mismatch")
+	ErrInvalidCurrency  = errors.New("tools: invalid currency")
+	ErrInvalidMoney     = errors.New("tools: invalid money string")
+	ErrInvalidRatios    = errors.New("tools: invalid allocation ratios")
+	ErrMoneyOutOfRange  = errors.New("tools: money out of range")
+)
+
+// Currency ISO 4217 alphabetic code, such as CNY, USD
+type Currency string
+
+const (
+	CNY Currency = "CNY"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	JPY Currency = "JPY"
+	HKD Currency = "HKD"
+	KRW Currency = "KRW"
+)
+
+type currencyInfo struct {
+	digits int32
+	symbol string
+}
+
+var (
+	_currencies = map[Currency]currencyInfo{
+		CNY:   {2, "¥"},
+		USD:   {2, "$"},
+		EUR:   {2, "€"},
+		GBP:   {2, "£"},
+		JPY:   {0, "¥"},
+		HKD:   {2, "HK$"},
+		KRW:   {0, "₩"},
+		"TWD": {2, "NT$"},
+		"SGD": {2, "S$"},
+		"AUD": {2, "A$"},
+		"CAD": {2, "C$"},
+		"CHF": {2, "CHF"},
+		"RUB": {2, "₽"},
+		"INR": {2, "₹"},
+		"KWD": {3, "KD"},
+		"BHD": {3, "BD"},
+	}
+	_currencyLocker sync.RWMutex
+)
+
+// RegisterCurrency adds or replaces a currency with the number of its minor unit digits and symbol
+func RegisterCurrency(code Currency, digits int32, symbol string) error {
+	if !code.IsValid() || digits < 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, code)
+	}
+	_currencyLocker.Lock()
+	defer _currencyLocker.Unlock()
+	_currencies[code] = currencyInfo{digits: digits, symbol: symbol}
+	return nil
+}
+
+func (c Currency) info() (currencyInfo, bool) {
+	_currencyLocker.RLock()
+	defer _currencyLocker.RUnlock()
+	info, ok := _currencies[c]
+	return info, ok
+}
+
+// IsValid reports whether c looks like an ISO 4217 code: 3 upper case letters
+func (c Currency) IsValid() bool {
+	if len(c) != 3 {
+		return false
+	}
+	for i := 0; i < len(c); i++ {
+		if c[i] < 'A' || c[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsKnown reports whether c is registered
+func (c Currency) IsKnown() bool {
+	_, ok := c.info()
+	return ok
+}
+
+// Digits number of digits of the minor unit, 2 for unknown currencies
+func (c Currency) Digits() int32 {
+	if info, ok := c.info(); ok {
+		return info.digits
+	}
+	return 2
+}
+
+// Symbol returns the currency symbol, or the code itself for unknown currencies
+func (c Currency) Symbol() string {
+	if info, ok := c.info(); ok && info.symbol != "" {
+		return info.symbol
+	}
+	return string(c)
+}
+
+func (c Currency) String() string { return string(c) }
+
+func ParseCurrency(s string) (Currency, error) {
+	c := Currency(S(s).Trim().ToUpper())
+	if !c.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, s)
+	}
+	return c, nil
+}
+
+// Money 金额和币种。不同币种之间的运算会返回ErrCurrencyMismatch。
+// 零值没有币种，可以与任意币种的金额相加。
+type Money struct {
+	amount   Decimal
+	currency Currency
+}
+
+func NewMoney(amount Decimal, currency Currency) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+// NewMoneyFromMinor creates money from its minor units, e.g. NewMoneyFromMinor(1250, CNY) is ¥12.50
+func NewMoneyFromMinor(minor int64, currency Currency) Money {
+	return Money{amount: Decimal{coef: big.NewInt(minor), scale: currency.Digits()}, currency: currency}
+}
+
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	d, err := ParseDecimal(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: d, currency: currency}, nil
+}
+
+// ParseMoneyString parses the output of Money.String(), like "12.50 CNY" or "CNY 12.50". A bare
+// amount like "12.50" is money without currency.
+func ParseMoneyString(s string) (Money, error) {
+	parts := strings.Fields(s)
+	if len(parts) == 1 {
+		return ParseMoney(parts[0], "")
+	}
+	if len(parts) != 2 {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	amount, code := parts[0], parts[1]
+	if _, err := ParseCurrency(amount); err == nil {
+		amount, code = code, amount
+	}
+	c, err := ParseCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return ParseMoney(amount, c)
+}
+
+func (m Money) Amount() Decimal     { return m.amount }
+func (m Money) Currency() Currency  { return m.currency }
+func (m Money) IsZero() bool        { return m.amount.IsZero() }
+func (m Money) Sign() int           { return m.amount.Sign() }
+func (m Money) IsNegative() bool    { return m.amount.IsNegative() }
+func (m Money) Neg() Money          { return Money{amount: m.amount.Neg(), currency: m.currency} }
+func (m Money) Abs() Money          { return Money{amount: m.amount.Abs(), currency: m.currency} }
+func (m Money) Mul(d Decimal) Money { return Money{amount: m.amount.Mul(d), currency: m.currency} }
+
+// SameCurrency reports whether m and o can be calculated together
+func (m Money) SameCurrency(o Money) bool {
+	return m.currency == o.currency || m.currency == "" || o.currency == ""
+}
+
+func (m Money) check(o Money) (Currency, error) {
+	if !m.SameCurrency(o) {
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	return IF(m.currency != "", m.currency, o.currency), nil
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	c, err := m.check(o)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: m.amount.Add(o.amount), currency: c}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	c, err := m.check(o)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: m.amount.Sub(o.amount), currency: c}, nil
+}
+
+func (m Money) Cmp(o Money) (int, error) {
+	if _, err := m.check(o); err != nil {
+		return 0, err
+	}
+	return m.amount.Cmp(o.amount), nil
+}
+
+func (m Money) Equal(o Money) bool {
+	return m.currency == o.currency && m.amount.Equal(o.amount)
+}
+
+// Round rounds the amount to the minor unit of the currency
+func (m Money) Round(mode RoundingMode) Money {
+	return Money{amount: m.amount.Round(m.currency.Digits(), mode), currency: m.currency}
+}
+
+// MinorUnits returns the amount in minor units (e.g. cents), exact is false if the amount has to be
+// rounded (RoundHalfUp) or overflows int64
+func (m Money) MinorUnits() (units int64, exact bool) {
+	digits := m.currency.Digits()
+	r := m.amount.Round(digits, RoundHalfUp)
+	c := r.bigCoef()
+	if !c.IsInt64() {
+		return 0, false
+	}
+	return c.Int64(), r.Equal(m.amount)
+}
+
+// Allocate splits m into len(ratios) parts proportional to ratios without losing any minor unit:
+// the remainders are given one unit each to the parties from the first. The amount is rounded to
+// the minor unit of the currency (RoundHalfEven) before allocation.
+// e.g. ¥0.05 allocated by (3, 7) is ¥0.02, ¥0.03; ¥100 allocated by (1, 1, 1) is ¥33.34, ¥33.33, ¥33.33
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, ErrInvalidRatios
+	}
+	sum := new(big.Int)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidRatios, r)
+		}
+		sum.Add(sum, big.NewInt(r))
+	}
+	if sum.Sign() == 0 {
+		return nil, fmt.Errorf("%w: sum of ratios is zero", ErrInvalidRatios)
+	}
+	digits := m.currency.Digits()
+	total := m.amount.Round(digits, RoundHalfEven).bigCoef()
+	neg := total.Sign() < 0
+	total = new(big.Int).Abs(total)
+
+	shares := make([]*big.Int, len(ratios))
+	left := new(big.Int).Set(total)
+	for i, r := range ratios {
+		shares[i] = new(big.Int).Mul(total, big.NewInt(r))
+		shares[i].Quo(shares[i], sum)
+		left.Sub(left, shares[i])
+	}
+	for i := 0; left.Sign() > 0; i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].Add(shares[i], bigOne)
+		left.Sub(left, bigOne)
+	}
+
+	ret := make([]Money, len(shares))
+	for i, s := range shares {
+		if neg {
+			s.Neg(s)
+		}
+		ret[i] = Money{amount: Decimal{coef: s, scale: digits}, currency: m.currency}
+	}
+	return ret, nil
+}
+
+// Split allocates m into n equal parts, see Allocate
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: %d parts", ErrInvalidRatios, n)
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// String like "12.50 CNY"
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.amount.String()
+	}
+	return m.amount.String() + " " + string(m.currency)
+}
+
+// MoneyFormat describes how a locale displays money
+type MoneyFormat struct {
+	GroupSeparator   string
+	DecimalSeparator string
+	SymbolAfter      bool   // symbol after the number, like "1.234,56 €"
+	SymbolSpace      string // between the symbol and the number
+	NegativePrefix   string // defaults to "-"
+}
+
+var MoneyFormats = map[string]MoneyFormat{
+	"en_US": {GroupSeparator: ",", DecimalSeparator: "."},
+	"en_GB": {GroupSeparator: ",", DecimalSeparator: "."},
+	"zh_CN": {GroupSeparator: ",", DecimalSeparator: "."},
+	"zh_HK": {GroupSeparator: ",", DecimalSeparator: "."},
+	"zh_TW": {GroupSeparator: ",", DecimalSeparator: "."},
+	"ja_JP": {GroupSeparator: ",", DecimalSeparator: "."},
+	"de_DE": {GroupSeparator: ".", DecimalSeparator: ",", SymbolAfter: true, SymbolSpace: " "},
+	"fr_FR": {GroupSeparator: "\u202f", DecimalSeparator: ",", SymbolAfter: true, SymbolSpace: "\u00a0"},
+	"de_CH": {GroupSeparator: "’", DecimalSeparator: ".", SymbolSpace: " "},
+	"ru_RU": {GroupSeparator: "\u00a0", DecimalSeparator: ",", SymbolAfter: true, SymbolSpace: "\u00a0"},
+}
+
+// Format formats m rounded (RoundHalfUp) to its minor unit, with the symbol of the currency
+func (f MoneyFormat) Format(m Money) string {
+	rounded := m.amount.Round(m.currency.Digits(), RoundHalfUp)
+	s := rounded.Abs().String()
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i := 0; i < len(intPart); i++ {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(f.GroupSeparator)
+		}
+		b.WriteByte(intPart[i])
+	}
+	if fracPart != "" {
+		b.WriteString(f.DecimalSeparator)
+		b.WriteString(fracPart)
+	}
+	number := b.String()
+	symbol := m.currency.Symbol()
+	if f.SymbolAfter {
+		number = number + f.SymbolSpace + symbol
+	} else {
+		number = symbol + f.SymbolSpace + number
+	}
+	if rounded.Sign() < 0 {
+		return IF(f.NegativePrefix != "", f.NegativePrefix, "-") + number
+	}
+	return number
+}
+
+// FormatLocale formats m by MoneyFormats[locale], en_US is used if the locale is unknown
+func (m Money) FormatLocale(locale string) string {
+	f, ok := MoneyFormats[strings.ReplaceAll(locale, "-", "_")]
+	if !ok {
+		f = MoneyFormats["en_US"]
+	}
+	return f.Format(m)
+}
+
+var (
+	_cnDigits       = []string{"零", "壹", "贰", "叁", "肆", "伍", "陆", "柒", "捌", "玖"}
+	_cnUnits        = []string{"仟", "佰", "拾", ""}
+	_cnSectionUnits = []string{"", "万", "亿", "万亿"}
+)
+
+// ChineseUpper 人民币大写金额，四舍五入到分，如 1004.50 为 "壹仟零肆元伍角"，100 为 "壹佰元整"。
+// 金额不能超过一万万亿。
+func (m Money) ChineseUpper() (string, error) {
+	fen := m.amount.Abs().Round(2, RoundHalfUp).bigCoef()
+	yuan, rem := new(big.Int).QuoRem(fen, big.NewInt(100), new(big.Int))
+	if yuan.Cmp(pow10(16)) >= 0 {
+		return "", ErrMoneyOutOfRange
+	}
+	jiao, f := rem.Int64()/10, rem.Int64()%10
+
+	var b strings.Builder
+	if m.amount.Round(2, RoundHalfUp).Sign() < 0 {
+		b.WriteString("负")
+	}
+	y := yuan.Int64()
+	if y > 0 {
+		var sections []int64
+		for v := y; v > 0; v /= 10000 {
+			sections = append(sections, v%10000)
+		}
+		needZero := false
+		for i := len(sections) - 1; i >= 0; i-- {
+			sec := sections[i]
+			if sec == 0 {
+				needZero = true
+				continue
+			}
+			if i < len(sections)-1 && (needZero || sec < 1000) {
+				b.WriteString("零")
+			}
+			needZero = false
+			started, zero := false, false
+			for j, div := 0, int64(1000); j < 4; j, div = j+1, div/10 {
+				d := sec / div % 10
+				if d == 0 {
+					zero = started
+					continue
+				}
+				if zero {
+					b.WriteString("零")
+				}
+				started, zero = true, false
+				b.WriteString(_cnDigits[d])
+				b.WriteString(_cnUnits[j])
+			}
+			b.WriteString(_cnSectionUnits[i])
+		}
+		b.WriteString("元")
+	}
+	switch {
+	case jiao == 0 && f == 0:
+		if y == 0 {
+			b.WriteString("零元")
+		}
+		b.WriteString("整")
+	case jiao == 0:
+		if y > 0 {
+			b.WriteString("零")
+		}
+		b.WriteString(_cnDigits[f] + "分")
+	default:
+		b.WriteString(_cnDigits[jiao] + "角")
+		if f > 0 {
+			b.WriteString(_cnDigits[f] + "分")
+		}
+	}
+	return b.String(), nil
+}
+
+type moneyJSON struct {
+	Amount   Decimal  `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// MarshalJSON like {"amount":"12.50","currency":"CNY"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.amount, Currency: m.currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var mj *moneyJSON
+	if err := json.Unmarshal(data, &mj); err != nil {
+		return err
+	}
+	if mj == nil {
+		return ErrNilSource
+	}
+	if mj.Currency != "" && !mj.Currency.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, mj.Currency)
+	}
+	m.amount, m.currency = mj.Amount, mj.Currency
+	return nil
+}
+
+// Scan accepts the format of String(), use Decimal for a column which only stores the amount
+func (m *Money) Scan(value any) error {
+	if m == nil {
+		return ErrNilValue
+	}
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return ErrNilSource
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return errors.New("tools: Money scan source was not []byte or string")
+	}
+	mm, err := ParseMoneyString(s)
+	if err != nil {
+		return err
+	}
+	*m = mm
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+type NullMoney = Null[Money]
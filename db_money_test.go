@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMoney_Calculate(t *testing.T) {
+	a, _ := ParseMoney("12.50", CNY)
+	b := NewMoneyFromMinor(199, CNY)
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "14.49 CNY" {
+		t.Fatalf("add: %s %v", sum, err)
+	}
+	if _, err = a.Add(NewMoneyFromMinor(1, USD)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("mismatch: %v", err)
+	}
+	if c, err := a.Cmp(b); err != nil || c != 1 {
+		t.Fatalf("cmp: %d %v", c, err)
+	}
+	if units, exact := NewMoney(MustParseDecimal("1.005"), USD).MinorUnits(); units != 101 || exact {
+		t.Fatalf("minor units: %d %t", units, exact)
+	}
+}
+
+func TestMoney_Allocate(t *testing.T) {
+	tests := []struct {
+		amount string
+		ratios []int64
+		want   []string
+	}{
+		{"100", []int64{1, 1, 1}, []string{"33.34", "33.33", "33.33"}},
+		{"0.05", []int64{3, 7}, []string{"0.02", "0.03"}},
+		{"-0.05", []int64{3, 7}, []string{"-0.02", "-0.03"}},
+		{"10", []int64{0, 1, 1}, []string{"0.00", "5.00", "5.00"}},
+		{"0.01", []int64{1, 1, 1}, []string{"0.01", "0.00", "0.00"}},
+	}
+	for _, test := range tests {
+		m, _ := ParseMoney(test.amount, CNY)
+		parts, err := m.Allocate(test.ratios...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := NewMoney(Decimal{}, CNY)
+		for i, p := range parts {
+			if p.Amount().String() != test.want[i] {
+				t.Fatalf("%s by %v: got %v, want %v", test.amount, test.ratios, parts, test.want)
+			}
+			total, _ = total.Add(p)
+		}
+		if !total.Equal(m) {
+			t.Fatalf("%s by %v: total %s", test.amount, test.ratios, total)
+		}
+	}
+	if _, err := NewMoneyFromMinor(1, CNY).Allocate(0, 0); !errors.Is(err, ErrInvalidRatios) {
+		t.Fatalf("zero ratios: %v", err)
+	}
+	if parts, err := NewMoneyFromMinor(100, JPY).Split(3); err != nil || parts[0].String() != "34 JPY" {
+		t.Fatalf("split: %v %v", parts, err)
+	}
+}
+
+func TestMoney_Format(t *testing.T) {
+	m, _ := ParseMoney("-1234567.891", EUR)
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en_US", "-€1,234,567.89"},
+		{"de_DE", "-1.234.567,89 €"},
+		{"fr-FR", "-1\u202f234\u202f567,89\u00a0€"},
+	}
+	for _, test := range tests {
+		if got := m.FormatLocale(test.locale); got != test.want {
+			t.Fatalf("%s: got %q, want %q", test.locale, got, test.want)
+		}
+	}
+	if got := NewMoneyFromMinor(1234, JPY).FormatLocale("ja_JP"); got != "¥1,234" {
+		t.Fatalf("ja_JP: %q", got)
+	}
+	if m, _ = ParseMoney("-0.001", CNY); m.FormatLocale("zh_CN") != "¥0.00" {
+		t.Fatalf("rounded to zero: %q", m.FormatLocale("zh_CN"))
+	}
+}
+
+func TestMoney_ChineseUpper(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   string
+	}{
+		{"0", "零元整"},
+		{"0.5", "伍角"},
+		{"0.05", "伍分"},
+		{"1", "壹元整"},
+		{"10", "壹拾元整"},
+		{"10.05", "壹拾元零伍分"},
+		{"100.50", "壹佰元伍角"},
+		{"1004.56", "壹仟零肆元伍角陆分"},
+		{"1010", "壹仟零壹拾元整"},
+		{"100000", "壹拾万元整"},
+		{"10001000", "壹仟万壹仟元整"},
+		{"100000001", "壹亿零壹元整"},
+		{"100010000", "壹亿零壹万元整"},
+		{"123456789.12", "壹亿贰仟叁佰肆拾伍万陆仟柒佰捌拾玖元壹角贰分"},
+		{"-3.999", "负肆元整"},
+	}
+	for _, test := range tests {
+		m, _ := ParseMoney(test.amount, CNY)
+		got, err := m.ChineseUpper()
+		if err != nil || got != test.want {
+			t.Fatalf("%s: got %q %v, want %q", test.amount, got, err, test.want)
+		}
+	}
+}
+
+func TestMoney_JSONAndSQL(t *testing.T) {
+	m, _ := ParseMoney("12.50", CNY)
+	bs, err := json.Marshal(m)
+	if err != nil || string(bs) != `{"amount":"12.50","currency":"CNY"}` {
+		t.Fatalf("marshal: %s %v", bs, err)
+	}
+	var mm Money
+	if err = json.Unmarshal(bs, &mm); err != nil || !mm.Equal(m) {
+		t.Fatalf("unmarshal: %s %v", mm, err)
+	}
+	v, _ := m.Value()
+	var sm NullMoney
+	if err = sm.Scan([]byte(v.(string))); err != nil || !sm.V.Equal(m) {
+		t.Fatalf("scan: %v %v", sm, err)
+	}
+	neg, _ := ParseMoney("-1.5", "")
+	for _, m := range []Money{{}, neg} {
+		v, _ = m.Value()
+		if err = mm.Scan(v); err != nil || !mm.Equal(m) || mm.Currency() != "" {
+			t.Fatalf("scan %v: %v %v", v, mm, err)
+		}
+	}
+}
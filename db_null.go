@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Null is a generic nullable value which can be used both as a database column and as a JSON field.
+// A Null value which is not Valid is written as SQL NULL and JSON null.
+// Null[T] has the same underlying type as sql.Null[T] (and NullString), so they can be converted
+// to each other directly.
+type Null[T any] sql.Null[T]
+
+type (
+	NullBool    = Null[bool]
+	NullFloat64 = Null[float64]
+	NullDate    = Null[Date]
+)
+
+// NewNull returns a valid Null with value v
+func NewNull[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true}
+}
+
+// NullFromPtr returns a Null which is null if p is nil, or the value p points to
+func NullFromPtr[T any](p *T) Null[T] {
+	if p == nil {
+		return Null[T]{}
+	}
+	return Null[T]{V: *p, Valid: true}
+}
+
+// ZeroNull zero value of T is Null, or not Null
+func ZeroNull[T comparable](v T) Null[T] {
+	var zero T
+	return Null[T]{V: v, Valid: v != zero}
+}
+
+// LE0Null Less or Equal to 0 is Null, or not Null
+func LE0Null[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64](v T) Null[T] {
+	if v <= 0 {
+		return Null[T]{}
+	}
+	return Null[T]{V: v, Valid: true}
+}
+
+func (n Null[T]) IsNull() bool { return !n.Valid }
+
+// Get returns the value and whether it is valid
+func (n Null[T]) Get() (T, bool) {
+	if n.Valid {
+		return n.V, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Or returns the value if it is valid, otherwise returns def
+func (n Null[T]) Or(def T) T {
+	if n.Valid {
+		return n.V
+	}
+	return def
+}
+
+// Ptr returns a pointer to a copy of the value, or nil if it is null
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	v := n.V
+	return &v
+}
+
+func (n *Null[T]) SetValue(v T) {
+	n.V = v
+	n.Valid = true
+}
+
+func (n *Null[T]) Clear() {
+	var zero T
+	n.V = zero
+	n.Valid = false
+}
+
+func (n *Null[T]) Scan(value any) error {
+	return (*sql.Null[T])(n).Scan(value)
+}
+
+func (n Null[T]) Value() (driver.Value, error) {
+	return sql.Null[T](n).Value()
+}
+
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.V)
+}
+
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		n.Clear()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n.SetValue(v)
+	return nil
+}
+
+func (n Null[T]) String() string {
+	if !n.Valid {
+		return ""
+	}
+	if s, ok := any(n.V).(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(n.V)
+}
+
+// Equal two null values are equal, or two valid values are equal. If T has an Equal(T) bool
+// method, it will be used to compare values, otherwise reflect.DeepEqual is used.
+func (n Null[T]) Equal(o Null[T]) bool {
+	if n.Valid != o.Valid {
+		return false
+	}
+	if !n.Valid {
+		return true
+	}
+	return equalValue(n.V, o.V)
+}
+
+func equalValue[T any](a, b T) bool {
+	if e, ok := any(a).(interface{ Equal(T) bool }); ok {
+		return e.Equal(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -68,6 +68,25 @@ func (i NullInt32) String() string {
 	return ""
 }
 
+func (i *NullInt32) SetValue(val int32) {
+	i.Int32 = val
+	i.Valid = true
+}
+
+func (i *NullInt32) Clear() {
+	i.Valid = false
+	i.Int32 = 0
+}
+
+func (i NullInt32) Or(def int32) int32 { return i.ToNull().Or(def) }
+func (i NullInt32) Ptr() *int32        { return i.ToNull().Ptr() }
+func (i NullInt32) Equal(o NullInt32) bool {
+	return i.Valid == o.Valid && (!i.Valid || i.Int32 == o.Int32)
+}
+func (i NullInt32) ToNull() Null[int32]     { return Null[int32]{V: i.Int32, Valid: i.Valid} }
+func NullInt32From(n Null[int32]) NullInt32 { return NullInt32{Int32: n.V, Valid: n.Valid} }
+func NullInt32FromPtr(p *int32) NullInt32   { return NullInt32From(NullFromPtr(p)) }
+
 type NullInt64 sql.NullInt64
 
 func NewNullInt64(i int64) NullInt64 {
@@ -151,3 +170,12 @@ func (i NullInt64) String() string {
 	}
 	return ""
 }
+
+func (i NullInt64) Or(def int64) int64 { return i.ToNull().Or(def) }
+func (i NullInt64) Ptr() *int64        { return i.ToNull().Ptr() }
+func (i NullInt64) Equal(o NullInt64) bool {
+	return i.Valid == o.Valid && (!i.Valid || i.Int64 == o.Int64)
+}
+func (i NullInt64) ToNull() Null[int64]     { return Null[int64]{V: i.Int64, Valid: i.Valid} }
+func NullInt64From(n Null[int64]) NullInt64 { return NullInt64{Int64: n.V, Valid: n.Valid} }
+func NullInt64FromPtr(p *int64) NullInt64   { return NullInt64From(NullFromPtr(p)) }
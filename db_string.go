@@ -58,3 +58,14 @@ func (n *NullString) UnmarshalJSON(data []byte) error {
 	}
 	return nil
 }
+
+func (n *NullString) SetValue(s string)      { (*Null[string])(n).SetValue(s) }
+func (n *NullString) Clear()                 { (*Null[string])(n).Clear() }
+func (n NullString) Or(def string) string    { return Null[string](n).Or(def) }
+func (n NullString) Ptr() *string            { return Null[string](n).Ptr() }
+func (n NullString) ToNull() Null[string]    { return Null[string](n) }
+func NullStringFromPtr(p *string) NullString { return NullString(NullFromPtr(p)) }
+
+func (n NullString) Equal(o NullString) bool {
+	return n.Valid == o.Valid && (!n.Valid || n.V == o.V)
+}
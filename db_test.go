@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -34,3 +35,64 @@ func TestDate(t *testing.T) {
 		t.Log(Time(test.input).String(), ":", Time(targetDate).String(), ":", Time(test.output).String())
 	}
 }
+
+func TestNull(t *testing.T) {
+	type row struct {
+		A Null[int64]     `json:"a"`
+		B NullBool        `json:"b"`
+		C NullDate        `json:"c"`
+		D Null[string]    `json:"d"`
+		E NullFloat64     `json:"e"`
+		F Null[time.Time] `json:"f"`
+	}
+	r := row{
+		A: NewNull[int64](12),
+		B: NewNull(true),
+		C: NewNull(NewADate(2025, 10, 8)),
+		D: ZeroNull(""),
+		E: LE0Null(0.0),
+	}
+	s, err := JsonString(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":12,"b":true,"c":"2025-10-08","d":null,"e":null,"f":null}`
+	if s != want {
+		t.Fatalf("got %s, want %s", s, want)
+	}
+	var rr row
+	if err = json.Unmarshal([]byte(s), &rr); err != nil {
+		t.Fatal(err)
+	}
+	if !rr.A.Equal(r.A) || !rr.B.Equal(r.B) || !rr.C.Equal(r.C) || !rr.D.Equal(r.D) || !rr.E.Equal(r.E) || !rr.F.Equal(r.F) {
+		t.Fatalf("got %+v, want %+v", rr, r)
+	}
+
+	var n Null[int64]
+	if err = n.Scan(int64(5)); err != nil || n.Or(0) != 5 {
+		t.Fatalf("scan failed: %v %v", n, err)
+	}
+	if v, err := n.Value(); err != nil || v != int64(5) {
+		t.Fatalf("value failed: %v %v", v, err)
+	}
+	if err = n.Scan(nil); err != nil || !n.IsNull() || n.Ptr() != nil {
+		t.Fatalf("scan nil failed: %v %v", n, err)
+	}
+	if v, err := n.Value(); err != nil || v != nil {
+		t.Fatalf("null value failed: %v %v", v, err)
+	}
+	i := int64(7)
+	if p := NullFromPtr(&i).Ptr(); p == nil || *p != 7 || p == &i {
+		t.Fatal("pointer conversion failed")
+	}
+	if NullInt64From(NewNull[int64](3)) != NewNullInt64(3) || LE0NullInt64(0).ToNull().Valid {
+		t.Fatal("NullInt64 interop failed")
+	}
+	if NewNullString("a", true).ToNull() != NewNull("a") || NullString(NewNull("b")).String() != "b" {
+		t.Fatal("NullString interop failed")
+	}
+	var d NullDate
+	if err = d.Scan(time.Date(2025, 10, 8, 0, 0, 0, 0, time.Local)); err != nil || d.String() != "2025-10-08" {
+		t.Fatalf("NullDate scan failed: %v %v", d, err)
+	}
+}
@@ -321,6 +321,25 @@ func (n NullTime) Equal(o NullTime) bool {
 	return n.Time.UnixMilli() == o.Time.UnixMilli()
 }
 
+func (n *NullTime) SetValue(t time.Time) {
+	*n = NewNullTime(t)
+}
+
+func (n *NullTime) Clear() {
+	*n = _nulltime
+}
+
+func (n NullTime) Or(def time.Time) time.Time { return n.ToNull().Or(def) }
+func (n NullTime) Ptr() *time.Time            { return n.ToNull().Ptr() }
+func (n NullTime) ToNull() Null[time.Time]    { return Null[time.Time]{V: n.Time, Valid: n.Valid} }
+func NullTimeFrom(n Null[time.Time]) NullTime {
+	if !n.Valid {
+		return _nulltime
+	}
+	return NewNullTime(n.V)
+}
+func NullTimeFromPtr(p *time.Time) NullTime { return NullTimeFrom(NullFromPtr(p)) }
+
 type Date time.Time
 
 func NewADate(year int, month time.Month, day int, loc ...*time.Location) Date {
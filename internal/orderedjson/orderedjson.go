@@ -0,0 +1,100 @@
+// Package orderedjson json objects which keep the order of their members, shared by tools and
+// tools/log
+package orderedjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Members members of a json object in their original order
+type Members []Member
+
+type Member struct {
+	Key   string
+	Value any
+}
+
+// Set replaces the value of key in place, or appends it if not found
+func (ms *Members) Set(key string, value any) {
+	for i := range *ms {
+		if (*ms)[i].Key == key {
+			(*ms)[i].Value = value
+			return
+		}
+	}
+	*ms = append(*ms, Member{Key: key, Value: value})
+}
+
+func (ms Members) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	// the output is compacted and escaped by the outer encoder
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, m := range ms {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(m.Key); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := enc.Encode(m.Value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// String the compact JSON of ms, so that text log formatters print it in the same order
+func (ms Members) String() string {
+	bs, err := json.Marshal(ms)
+	if err != nil {
+		return fmt.Sprintf("%v", []Member(ms))
+	}
+	return string(bs)
+}
+
+// Decode decodes the next value of dec like json.Decoder.Decode into any, but objects are decoded
+// as Members
+func Decode(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		ms := Members{}
+		for dec.More() {
+			if tok, err = dec.Token(); err != nil {
+				return nil, err
+			}
+			v, err := Decode(dec)
+			if err != nil {
+				return nil, err
+			}
+			ms = append(ms, Member{Key: tok.(string), Value: v})
+		}
+		_, err = dec.Token()
+		return ms, err
+	case '[':
+		vs := []any{}
+		for dec.More() {
+			v, err := Decode(dec)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+		_, err = dec.Token()
+		return vs, err
+	}
+	return nil, fmt.Errorf("tools: unexpected json delimiter %v", delim)
+}
@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// CanonicalJSON returns the RFC 8785 JSON Canonicalization Scheme (JCS) form of data: no whitespace,
+// object members sorted by the UTF-16 code units of their names, strings with minimal escaping and
+// numbers in the ECMAScript format of IEEE 754 doubles (so integers beyond 2^53 lose precision).
+func CanonicalJSON(data []byte) ([]byte, error) {
+	v, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err = writeCanonicalJSON(buf, v, false); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCanonicalJSON writes v in the form of CanonicalJSON, or with numbers in the exact normalized
+// decimal form if exact
+func writeCanonicalJSON(buf *bytes.Buffer, v any, exact bool) error {
+	switch c := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(c))
+	case string:
+		writeCanonicalString(buf, c)
+	case json.Number:
+		if exact {
+			buf.WriteString(exactNumber(c))
+			break
+		}
+		f, err := strconv.ParseFloat(string(c), 64)
+		if err != nil {
+			return fmt.Errorf("tools: canonical json number %s: %w", c, err)
+		}
+		s, err := canonicalNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case []any:
+		buf.WriteByte('[')
+		for i, e := range c {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, e, exact); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := slices.SortedFunc(KMap[string, any](c).KeySeq(), compareUTF16)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, c[k], exact); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("tools: unsupported json value type %T", v)
+	}
+	return nil
+}
+
+func compareUTF16(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\b':
+			buf.WriteString(`\b`)
+		case r == '\f':
+			buf.WriteString(`\f`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hexDigits[r>>4])
+			buf.WriteByte(hexDigits[r&0xf])
+		default:
+			buf.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+}
+
+// exactNumber returns the same string for numbers equal in SemanticEqual: the normalized decimal
+// ("1.0", "1e0" and "1" are all "1"), or n itself if it can't be parsed as a Decimal, which is only
+// equal to the same string in SemanticEqual.
+func exactNumber(n json.Number) string {
+	d, err := ParseDecimal(string(n))
+	if err != nil {
+		return string(n)
+	}
+	return d.Normalize().String()
+}
+
+// canonicalNumber formats f like Number.prototype.toString() of ECMAScript
+func canonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("tools: %v is not allowed in canonical json", f)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	// shortest round-trip digits and exponent: d.ddde±x
+	mantissa, expStr, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	exp, _ := strconv.Atoi(expStr)
+	digits := strings.Replace(mantissa, ".", "", 1)
+	k, n := len(digits), exp+1
+	var s string
+	switch {
+	case k <= n && n <= 21:
+		s = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		s = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		s = "0." + strings.Repeat("0", -n) + digits
+	default:
+		s = digits[:1]
+		if k > 1 {
+			s += "." + digits[1:]
+		}
+		s += "e" + IF(n-1 >= 0, "+", "-") + strconv.Itoa(Abs(n-1))
+	}
+	return sign + s, nil
+}
+
+// Canonical returns the RFC 8785 canonical form of j, see CanonicalJSON. A null JSON stays null.
+func (j JSON) Canonical() (JSON, error) {
+	if j.IsNull() {
+		return nil, nil
+	}
+	return CanonicalJSON(j)
+}
+
+// SemanticEqual reports whether j and o represent the same value, ignoring whitespace, the order of
+// object members and the format of numbers (1.0 equals to 1 and 1e2). Invalid JSON values are
+// compared byte by byte. Numbers are compared exactly as decimals, so 9007199254740993 is not equal
+// to 9007199254740992.
+func (j JSON) SemanticEqual(o JSON) bool {
+	a, err := decodeJSONValue(j)
+	if err != nil {
+		return bytes.Equal(j, o)
+	}
+	b, err := decodeJSONValue(o)
+	if err != nil {
+		return false
+	}
+	return equalJSONValue(a, b)
+}
+
+// CanonicalHash returns the hex encoded SHA-256 of the canonical form, which is stable across key
+// order and formatting changes. A null JSON is hashed as "null". Unlike CanonicalJSON, numbers are
+// hashed exactly as normalized decimals, so that equal hashes mean SemanticEqual even for integers
+// beyond 2^53.
+func (j JSON) CanonicalHash() (string, error) {
+	if j.IsNull() {
+		j = JSON("null")
+	}
+	v, err := decodeJSONValue(j)
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if err = writeCanonicalJSON(buf, v, true); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(Hash256(sha256.New(), buf.Bytes())), nil
+}
+
+func (n NotNullJSON) Canonical() (NotNullJSON, error) {
+	c, err := CanonicalJSON(n.Bytes())
+	return NotNullJSON(c), err
+}
+
+func (n NotNullJSON) SemanticEqual(o NotNullJSON) bool {
+	return JSON(n.Bytes()).SemanticEqual(o.Bytes())
+}
+
+func (n NotNullJSON) CanonicalHash() (string, error) {
+	return JSON(n.Bytes()).CanonicalHash()
+}
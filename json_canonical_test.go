@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			`{"numbers":[333333333.33333329,1E30,4.50,2e-3,0.000000000000000000000000001],"string":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","literals":[null,true,false]}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			`{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{`[-0, 1E21, 1E20, 9007199254740992, 5e-324, 1.7976931348623157e308, 0.000001, 1e-7, -12.50]`,
+			`[0,1e+21,100000000000000000000,9007199254740992,5e-324,1.7976931348623157e+308,0.000001,1e-7,-12.5]`},
+		{` { "b" : [ 1 , { "d":"<&>", "c":2 } ], "a" : {} } `, `{"a":{},"b":[1,{"c":2,"d":"<&>"}]}`},
+	}
+	for _, test := range tests {
+		got, err := JSON(test.input).Canonical()
+		if err != nil || string(got) != test.want {
+			t.Fatalf("input %s:\n got %s %v\nwant %s", test.input, got, err, test.want)
+		}
+	}
+	if _, err := JSON(`{"a":1e400}`).Canonical(); err == nil {
+		t.Fatal("out of range number should fail")
+	}
+}
+
+func TestJSON_SemanticEqual(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, true},
+		{`{"a":1.0,"b":[1e2]}`, ` {"b":[100], "a":1}`, true},
+		{`{"a":1}`, `{"a":"1"}`, false},
+		{`[1,2]`, `[2,1]`, false},
+		{`{"a":null}`, `{}`, false},
+		{`{"id":9007199254740993}`, `{"id":9007199254740992}`, false},
+		{`[12345678901234567890, -0, 1.50]`, `[1.234567890123456789e19, 0, 1.5]`, true},
+	}
+	for _, test := range tests {
+		if got := JSON(test.a).SemanticEqual(JSON(test.b)); got != test.equal {
+			t.Fatalf("%s vs %s: got %t", test.a, test.b, got)
+		}
+		ha, _ := JSON(test.a).CanonicalHash()
+		hb, _ := JSON(test.b).CanonicalHash()
+		if (ha == hb) != test.equal {
+			t.Fatalf("%s vs %s: hash %s %s", test.a, test.b, ha, hb)
+		}
+	}
+	var n1, n2 NotNullJSON
+	if !n1.SemanticEqual(n2) || !NotNullJSON(`{ }`).SemanticEqual(n1) {
+		t.Fatal("not null json should equal to {}")
+	}
+}
@@ -0,0 +1,273 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+var ErrJSONPatchTestFailed = errors.New("tools: json patch test failed")
+
+const (
+	JSONPatchAdd     = "add"
+	JSONPatchRemove  = "remove"
+	JSONPatchReplace = "replace"
+	JSONPatchMove    = "move"
+	JSONPatchCopy    = "copy"
+	JSONPatchTest    = "test"
+)
+
+// JSONPatchOp one operation of RFC 6902 JSON Patch
+type JSONPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch RFC 6902 JSON Patch document
+type JSONPatch []JSONPatchOp
+
+func ParseJSONPatch(data []byte) (JSONPatch, error) {
+	var p JSONPatch
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (op JSONPatchOp) value() (any, error) {
+	if len(op.Value) == 0 {
+		return nil, fmt.Errorf("tools: json patch %s %s: missing value", op.Op, op.Path)
+	}
+	return decodeJSONValue(op.Value)
+}
+
+func (op JSONPatchOp) apply(doc any) (any, error) {
+	path, err := ParseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case JSONPatchAdd:
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return path.add(doc, v)
+	case JSONPatchRemove:
+		return path.remove(doc)
+	case JSONPatchReplace:
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = path.get(doc); err != nil {
+			return nil, err
+		}
+		if doc, err = path.remove(doc); err != nil {
+			return nil, err
+		}
+		return path.add(doc, v)
+	case JSONPatchMove, JSONPatchCopy:
+		from, err := ParseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := from.get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == JSONPatchMove {
+			if len(path) > len(from) && slices.Equal(path[:len(from)], from) {
+				return nil, fmt.Errorf("tools: json patch move %s into its child %s", op.From, op.Path)
+			}
+			if doc, err = from.remove(doc); err != nil {
+				return nil, err
+			}
+		} else {
+			v = cloneJSONValue(v)
+		}
+		return path.add(doc, v)
+	case JSONPatchTest:
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		cur, err := path.get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !equalJSONValue(cur, v) {
+			return nil, fmt.Errorf("%w: %s", ErrJSONPatchTestFailed, op.Path)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("tools: unknown json patch operation %q", op.Op)
+	}
+}
+
+// Apply applies all operations to doc atomically: doc is not modified and an error is returned if
+// any operation fails.
+func (p JSONPatch) Apply(doc JSON) (JSON, error) {
+	v, err := decodeJSONValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range p {
+		if v, err = op.apply(v); err != nil {
+			return nil, fmt.Errorf("tools: json patch operation %d: %w", i, err)
+		}
+	}
+	return encodeJSONValue(v)
+}
+
+// ApplyPatch applies RFC 6902 JSON Patch to j and returns the result, j is not modified
+func (j JSON) ApplyPatch(patch JSONPatch) (JSON, error) {
+	return patch.Apply(j)
+}
+
+// MergePatch applies RFC 7396 JSON Merge Patch to j and returns the result, j is not modified
+func (j JSON) MergePatch(patch JSON) (JSON, error) {
+	doc, err := decodeJSONValue(j)
+	if err != nil {
+		return nil, err
+	}
+	pv, err := decodeJSONValue(patch)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJSONValue(mergePatchValue(doc, pv))
+}
+
+func mergePatchValue(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = make(map[string]any, len(pm))
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = mergePatchValue(tm[k], v)
+		}
+	}
+	return tm
+}
+
+// CreateMergePatch generates a RFC 7396 JSON Merge Patch which turns from into to. Because null
+// means removal in merge patch, null members of objects in to cannot be represented.
+func CreateMergePatch(from, to JSON) (JSON, error) {
+	fv, err := decodeJSONValue(from)
+	if err != nil {
+		return nil, err
+	}
+	tv, err := decodeJSONValue(to)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJSONValue(createMergePatchValue(fv, tv))
+}
+
+func createMergePatchValue(from, to any) any {
+	fm, ok1 := from.(map[string]any)
+	tm, ok2 := to.(map[string]any)
+	if !ok1 || !ok2 {
+		return to
+	}
+	patch := make(map[string]any)
+	for k := range fm {
+		if _, exist := tm[k]; !exist {
+			patch[k] = nil
+		}
+	}
+	for k, tv := range tm {
+		fv, exist := fm[k]
+		if !exist {
+			patch[k] = tv
+		} else if !equalJSONValue(fv, tv) {
+			patch[k] = createMergePatchValue(fv, tv)
+		}
+	}
+	return patch
+}
+
+// DiffJSON generates a RFC 6902 JSON Patch which turns from into to
+func DiffJSON(from, to JSON) (JSONPatch, error) {
+	fv, err := decodeJSONValue(from)
+	if err != nil {
+		return nil, err
+	}
+	tv, err := decodeJSONValue(to)
+	if err != nil {
+		return nil, err
+	}
+	var patch JSONPatch
+	if err = diffJSONValue(JSONPointer{}, fv, tv, &patch); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+func diffJSONValue(path JSONPointer, from, to any, patch *JSONPatch) error {
+	if equalJSONValue(from, to) {
+		return nil
+	}
+	valueOp := func(op string, p JSONPointer, v any) error {
+		bs, err := encodeJSONValue(v)
+		if err != nil {
+			return err
+		}
+		*patch = append(*patch, JSONPatchOp{Op: op, Path: p.String(), Value: json.RawMessage(bs)})
+		return nil
+	}
+	switch f := from.(type) {
+	case map[string]any:
+		t, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+		for _, k := range slices.Sorted(KMap[string, any](f).KeySeq()) {
+			if _, exist := t[k]; !exist {
+				*patch = append(*patch, JSONPatchOp{Op: JSONPatchRemove, Path: path.Append(k).String()})
+			}
+		}
+		for _, k := range slices.Sorted(KMap[string, any](t).KeySeq()) {
+			if fv, exist := f[k]; exist {
+				if err := diffJSONValue(path.Append(k), fv, t[k], patch); err != nil {
+					return err
+				}
+			} else if err := valueOp(JSONPatchAdd, path.Append(k), t[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		t, ok := to.([]any)
+		if !ok {
+			break
+		}
+		common := min(len(f), len(t))
+		for i := 0; i < common; i++ {
+			if err := diffJSONValue(path.Append(strconv.Itoa(i)), f[i], t[i], patch); err != nil {
+				return err
+			}
+		}
+		for i := len(f) - 1; i >= common; i-- {
+			*patch = append(*patch, JSONPatchOp{Op: JSONPatchRemove, Path: path.Append(strconv.Itoa(i)).String()})
+		}
+		for i := common; i < len(t); i++ {
+			if err := valueOp(JSONPatchAdd, path.Append(strconv.Itoa(i)), t[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return valueOp(JSONPatchReplace, path, to)
+}
@@ -0,0 +1,581 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrJSONPathNotFound = errors.New("tools: json path not found")
+	ErrInvalidJSONPath  = errors.New("tools: invalid json path")
+)
+
+// decodeJSONValue decodes data into map[string]any, []any, json.Number, string, bool or nil.
+// Empty data is decoded as nil (JSON null).
+func decodeJSONValue(data []byte) (any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("tools: invalid character after top-level json value")
+	}
+	return v, nil
+}
+
+// encodeJSONValue marshals v compactly without escaping HTML characters
+func encodeJSONValue(v any) (JSON, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return JSON(bytes.TrimRight(buf.Bytes(), "\n")), nil
+}
+
+// cloneJSONValue deep copies a decoded json value
+func cloneJSONValue(v any) any {
+	switch c := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(c))
+		for k, vv := range c {
+			m[k] = cloneJSONValue(vv)
+		}
+		return m
+	case []any:
+		a := make([]any, len(c))
+		for i, vv := range c {
+			a[i] = cloneJSONValue(vv)
+		}
+		return a
+	default:
+		return v
+	}
+}
+
+// equalJSONValue compares decoded json values, numbers are compared by their values
+func equalJSONValue(a, b any) bool {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, exist := y[k]
+			if !exist || !equalJSONValue(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalJSONValue(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		dx, err1 := ParseDecimal(string(x))
+		dy, err2 := ParseDecimal(string(y))
+		return err1 == nil && err2 == nil && dx.Equal(dy)
+	default:
+		return a == b
+	}
+}
+
+// JSONPointer RFC 6901 JSON Pointer, each element is an unescaped reference token.
+// An empty JSONPointer refers to the whole document.
+type JSONPointer []string
+
+func ParseJSONPointer(s string) (JSONPointer, error) {
+	if s == "" {
+		return JSONPointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("%w: json pointer must start with '/': %q", ErrInvalidJSONPath, s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(t, "~0", ""), "~1", ""), "~") {
+			return nil, fmt.Errorf("%w: invalid escape in json pointer: %q", ErrInvalidJSONPath, s)
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// Append returns a new pointer with tokens appended
+func (p JSONPointer) Append(tokens ...string) JSONPointer {
+	r := make(JSONPointer, 0, len(p)+len(tokens))
+	return append(append(r, p...), tokens...)
+}
+
+func (p JSONPointer) String() string {
+	var b strings.Builder
+	for _, t := range p {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+func jsonArrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidJSONPath, token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidJSONPath, token)
+	}
+	if i > length || (i == length && !allowEnd) {
+		return 0, fmt.Errorf("%w: array index %d out of range", ErrJSONPathNotFound, i)
+	}
+	return i, nil
+}
+
+func (p JSONPointer) get(doc any) (any, error) {
+	cur := doc
+	for i, t := range p {
+		switch c := cur.(type) {
+		case map[string]any:
+			v, ok := c[t]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrJSONPathNotFound, p[:i+1])
+			}
+			cur = v
+		case []any:
+			idx, err := jsonArrayIndex(t, len(c), false)
+			if err != nil {
+				return nil, fmt.Errorf("%w at %s", err, p[:i+1])
+			}
+			cur = c[idx]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrJSONPathNotFound, p[:i+1])
+		}
+	}
+	return cur, nil
+}
+
+// modify walks to the parent of the last token and replaces it with the result of op, p must not be empty
+func (p JSONPointer) modify(doc any, op func(parent any, token string) (any, error)) (any, error) {
+	if len(p) == 1 {
+		return op(doc, p[0])
+	}
+	switch c := doc.(type) {
+	case map[string]any:
+		child, ok := c[p[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: /%s", ErrJSONPathNotFound, p[0])
+		}
+		nc, err := p[1:].modify(child, op)
+		if err != nil {
+			return nil, err
+		}
+		c[p[0]] = nc
+		return c, nil
+	case []any:
+		idx, err := jsonArrayIndex(p[0], len(c), false)
+		if err != nil {
+			return nil, err
+		}
+		nc, err := p[1:].modify(c[idx], op)
+		if err != nil {
+			return nil, err
+		}
+		c[idx] = nc
+		return c, nil
+	default:
+		return nil, fmt.Errorf("%w: /%s is not a container", ErrJSONPathNotFound, p[0])
+	}
+}
+
+// add RFC 6902 "add": members are set, array elements are inserted
+func (p JSONPointer) add(doc, value any) (any, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+	return p.modify(doc, func(parent any, token string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			c[token] = value
+			return c, nil
+		case []any:
+			idx, err := jsonArrayIndex(token, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			return slices.Insert(c, idx, value), nil
+		default:
+			return nil, fmt.Errorf("%w: parent of %s is not a container", ErrJSONPathNotFound, p)
+		}
+	})
+}
+
+// set like add, but array elements are replaced instead of inserted, and missing (or null) parents
+// are created as objects
+func (p JSONPointer) set(doc, value any) (any, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	switch c := doc.(type) {
+	case map[string]any:
+		nc, err := p[1:].set(c[p[0]], value)
+		if err != nil {
+			return nil, err
+		}
+		c[p[0]] = nc
+		return c, nil
+	case []any:
+		idx, err := jsonArrayIndex(p[0], len(c), true)
+		if err != nil {
+			return nil, err
+		}
+		if idx == len(c) {
+			c = append(c, nil)
+		}
+		nc, err := p[1:].set(c[idx], value)
+		if err != nil {
+			return nil, err
+		}
+		c[idx] = nc
+		return c, nil
+	default:
+		return nil, fmt.Errorf("%w: %s is not a container", ErrJSONPathNotFound, p[:1])
+	}
+}
+
+func (p JSONPointer) remove(doc any) (any, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	return p.modify(doc, func(parent any, token string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrJSONPathNotFound, p)
+			}
+			delete(c, token)
+			return c, nil
+		case []any:
+			idx, err := jsonArrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			return slices.Delete(c, idx, idx+1), nil
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrJSONPathNotFound, p)
+		}
+	})
+}
+
+type jsonPathSegment struct {
+	descendant bool   // ..
+	wildcard   bool   // * or [*]
+	name       string // .name or ['name']
+	index      *int   // [n], negative counts from the end
+}
+
+// parseJSONPath parses a JSONPath subset: $, .name, ['name'], ["name"], [n], [-n], .*, [*] and ..
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	p := strings.TrimSpace(path)
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("%w: must start with '$': %q", ErrInvalidJSONPath, path)
+	}
+	var segs []jsonPathSegment
+	for i := 1; i < len(p); {
+		var seg jsonPathSegment
+		switch p[i] {
+		case '.':
+			i++
+			if i < len(p) && p[i] == '.' {
+				seg.descendant = true
+				i++
+			}
+			if i >= len(p) {
+				return nil, fmt.Errorf("%w: unexpected end: %q", ErrInvalidJSONPath, path)
+			}
+			if p[i] == '[' {
+				if !seg.descendant {
+					return nil, fmt.Errorf("%w: unexpected '[' after '.': %q", ErrInvalidJSONPath, path)
+				}
+				continue
+			}
+			j := i
+			for j < len(p) && p[j] != '.' && p[j] != '[' {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("%w: empty name at %d: %q", ErrInvalidJSONPath, i, path)
+			}
+			if p[i:j] == "*" {
+				seg.wildcard = true
+			} else {
+				seg.name = p[i:j]
+			}
+			i = j
+		case '[':
+			j := strings.IndexByte(p[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("%w: missing ']': %q", ErrInvalidJSONPath, path)
+			}
+			if p[i-1] == '.' {
+				seg.descendant = true
+			}
+			inner := strings.TrimSpace(p[i+1 : i+j])
+			switch {
+			case inner == "*":
+				seg.wildcard = true
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				seg.name = inner[1 : len(inner)-1]
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("%w: invalid subscript %q: %q", ErrInvalidJSONPath, inner, path)
+				}
+				seg.index = &n
+			}
+			i += j + 1
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at %d: %q", ErrInvalidJSONPath, p[i], i, path)
+		}
+		segs = append(segs, seg)
+	}
+	return segs, nil
+}
+
+func jsonDescendants(v any, out []any) []any {
+	out = append(out, v)
+	switch c := v.(type) {
+	case map[string]any:
+		for _, k := range slices.Sorted(KMap[string, any](c).KeySeq()) {
+			out = jsonDescendants(c[k], out)
+		}
+	case []any:
+		for _, vv := range c {
+			out = jsonDescendants(vv, out)
+		}
+	}
+	return out
+}
+
+func (s jsonPathSegment) selectFrom(v any, out []any) []any {
+	switch c := v.(type) {
+	case map[string]any:
+		if s.wildcard {
+			for _, k := range slices.Sorted(KMap[string, any](c).KeySeq()) {
+				out = append(out, c[k])
+			}
+		} else if s.index == nil {
+			if vv, ok := c[s.name]; ok {
+				out = append(out, vv)
+			}
+		}
+	case []any:
+		if s.wildcard {
+			out = append(out, c...)
+		} else if s.index != nil {
+			idx := *s.index
+			if idx < 0 {
+				idx += len(c)
+			}
+			if idx >= 0 && idx < len(c) {
+				out = append(out, c[idx])
+			}
+		}
+	}
+	return out
+}
+
+func queryJSONPath(doc any, path string) ([]any, error) {
+	segs, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []any{doc}
+	for _, seg := range segs {
+		if seg.descendant {
+			var all []any
+			for _, n := range nodes {
+				all = jsonDescendants(n, all)
+			}
+			nodes = all
+		}
+		var next []any
+		for _, n := range nodes {
+			next = seg.selectFrom(n, next)
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// lookup path is a JSONPath if it starts with '$', and the first match is returned.
+// Otherwise it is a JSON Pointer.
+func (j JSON) lookup(path string) (any, error) {
+	doc, err := decodeJSONValue(j)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(path), "$") {
+		nodes, err := queryJSONPath(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if len(nodes) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrJSONPathNotFound, path)
+		}
+		return nodes[0], nil
+	}
+	p, err := ParseJSONPointer(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.get(doc)
+}
+
+// Query returns all values matched by the JSONPath expression, such as "$.items[*].id", "$..name",
+// "$['a b'][0]" or "$.list[-1]"
+func (j JSON) Query(path string) ([]JSON, error) {
+	doc, err := decodeJSONValue(j)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := queryJSONPath(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]JSON, 0, len(nodes))
+	for _, n := range nodes {
+		bs, err := encodeJSONValue(n)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, bs)
+	}
+	return ret, nil
+}
+
+// Get returns the value at path, which is either a JSON Pointer (e.g. "/a/0/b") or a JSONPath
+// expression (e.g. "$.a[0].b", the first match is returned)
+func (j JSON) Get(path string) (JSON, error) {
+	v, err := j.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJSONValue(v)
+}
+
+// Exists reports whether there's a value (including null) at path
+func (j JSON) Exists(path string) bool {
+	_, err := j.lookup(path)
+	return err == nil
+}
+
+// JSONGet unmarshals the value at path of j into T, see JSON.Get for the path format
+func JSONGet[T any](j JSON, path string) (T, error) {
+	var t T
+	v, err := j.Get(path)
+	if err != nil {
+		return t, err
+	}
+	if err = json.Unmarshal(v, &t); err != nil {
+		return t, fmt.Errorf("tools: json value at %s: %w", path, err)
+	}
+	return t, nil
+}
+
+func (j JSON) GetString(path string) (string, error)   { return JSONGet[string](j, path) }
+func (j JSON) GetInt64(path string) (int64, error)     { return JSONGet[int64](j, path) }
+func (j JSON) GetFloat64(path string) (float64, error) { return JSONGet[float64](j, path) }
+func (j JSON) GetBool(path string) (bool, error)       { return JSONGet[bool](j, path) }
+func (j JSON) GetDecimal(path string) (Decimal, error) { return JSONGet[Decimal](j, path) }
+
+// Set returns a new JSON with value set at the JSON Pointer, missing object members are created.
+// For arrays, the element is replaced, and "-" appends to the end. value can be a JSON or any value
+// which can be marshalled. j is not modified. Object keys of the result are sorted.
+func (j JSON) Set(pointer string, value any) (JSON, error) {
+	p, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeJSONValue(j)
+	if err != nil {
+		return nil, err
+	}
+	v, err := toJSONValue(value)
+	if err != nil {
+		return nil, err
+	}
+	if doc, err = p.set(doc, v); err != nil {
+		return nil, err
+	}
+	return encodeJSONValue(doc)
+}
+
+// Delete returns a new JSON without the value at the JSON Pointer, j is not modified.
+func (j JSON) Delete(pointer string) (JSON, error) {
+	p, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeJSONValue(j)
+	if err != nil {
+		return nil, err
+	}
+	if doc, err = p.remove(doc); err != nil {
+		return nil, err
+	}
+	if doc == nil && len(p) == 0 {
+		return nil, nil
+	}
+	return encodeJSONValue(doc)
+}
+
+// toJSONValue converts value to a decoded json value by marshalling, JSON values are decoded directly
+func toJSONValue(value any) (any, error) {
+	var bs []byte
+	switch v := value.(type) {
+	case JSON:
+		bs = v
+	case NotNullJSON:
+		bs = v.Bytes()
+	case json.RawMessage:
+		bs = v
+	default:
+		var err error
+		if bs, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return decodeJSONValue(bs)
+}
@@ -0,0 +1,218 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONPointer(t *testing.T) {
+	doc := JSON(`{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`)
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{"/foo", `["bar","baz"]`},
+		{"/foo/0", `"bar"`},
+		{"/", `0`},
+		{"/a~1b", `1`},
+		{"/c%d", `2`},
+		{"/e^f", `3`},
+		{"/g|h", `4`},
+		{"/i\\j", `5`},
+		{"/k\"l", `6`},
+		{"/ ", `7`},
+		{"/m~0n", `8`},
+	}
+	for _, test := range tests {
+		got, err := doc.Get(test.pointer)
+		if err != nil || string(got) != test.want {
+			t.Fatalf("%s: got %s %v, want %s", test.pointer, got, err, test.want)
+		}
+	}
+	p, _ := ParseJSONPointer("/a~1b/m~0n/0")
+	if p.String() != "/a~1b/m~0n/0" || len(p) != 3 || p[0] != "a/b" || p[1] != "m~n" {
+		t.Fatalf("parse pointer: %v", p)
+	}
+	if _, err := doc.Get("/foo/2"); !errors.Is(err, ErrJSONPathNotFound) {
+		t.Fatalf("out of range: %v", err)
+	}
+	if _, err := doc.Get("/foo/01"); !errors.Is(err, ErrInvalidJSONPath) {
+		t.Fatalf("leading zero: %v", err)
+	}
+}
+
+func TestJSON_Query(t *testing.T) {
+	doc := JSON(`{"store":{"book":[{"title":"A","price":8.95},{"title":"B","price":12.99,"tags":["x"]}],"bicycle":{"price":19.95}},"n":null}`)
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$.store.book[0].title", []string{`"A"`}},
+		{"$.store.book[-1].title", []string{`"B"`}},
+		{"$['store']['bicycle'].price", []string{`19.95`}},
+		{"$.store.book[*].title", []string{`"A"`, `"B"`}},
+		{"$..price", []string{`19.95`, `8.95`, `12.99`}},
+		{"$..[0]", []string{`{"price":8.95,"title":"A"}`, `"x"`}},
+		{"$.store.*.price", []string{`19.95`}},
+		{"$.n", []string{`null`}},
+		{"$.none", []string{}},
+	}
+	for _, test := range tests {
+		got, err := doc.Query(test.path)
+		if err != nil {
+			t.Fatalf("%s: %v", test.path, err)
+		}
+		if len(got) != len(test.want) {
+			t.Fatalf("%s: got %s, want %v", test.path, got, test.want)
+		}
+		for i := range got {
+			if string(got[i]) != test.want[i] {
+				t.Fatalf("%s: got %s, want %v", test.path, got, test.want)
+			}
+		}
+	}
+	if s, err := doc.GetString("$.store.book[1].title"); err != nil || s != "B" {
+		t.Fatalf("get string: %s %v", s, err)
+	}
+	if d, err := doc.GetDecimal("/store/bicycle/price"); err != nil || d.String() != "19.95" {
+		t.Fatalf("get decimal: %s %v", d, err)
+	}
+	if _, err := doc.GetInt64("$.store.book[0].title"); err == nil {
+		t.Fatal("type mismatch should fail")
+	}
+	if !doc.Exists("/n") || doc.Exists("/none") {
+		t.Fatal("exists failed")
+	}
+	if _, err := doc.Query("store.book"); !errors.Is(err, ErrInvalidJSONPath) {
+		t.Fatalf("invalid path: %v", err)
+	}
+}
+
+func TestJSON_SetDelete(t *testing.T) {
+	doc := JSON(`{"a":{"b":[1,2]},"c":"<x>"}`)
+	got, err := doc.Set("/a/b/1", 3)
+	if err != nil || string(got) != `{"a":{"b":[1,3]},"c":"<x>"}` {
+		t.Fatalf("set: %s %v", got, err)
+	}
+	if got, err = doc.Set("/a/b/-", JSON(`{"k":true}`)); err != nil || string(got) != `{"a":{"b":[1,2,{"k":true}]},"c":"<x>"}` {
+		t.Fatalf("append: %s %v", got, err)
+	}
+	if got, err = doc.Set("/x/y", "z"); err != nil || string(got) != `{"a":{"b":[1,2]},"c":"<x>","x":{"y":"z"}}` {
+		t.Fatalf("create: %s %v", got, err)
+	}
+	if got, err = doc.Delete("/a/b/0"); err != nil || string(got) != `{"a":{"b":[2]},"c":"<x>"}` {
+		t.Fatalf("delete: %s %v", got, err)
+	}
+	if _, err = doc.Delete("/a/z"); !errors.Is(err, ErrJSONPathNotFound) {
+		t.Fatalf("delete missing: %v", err)
+	}
+	if string(doc) != `{"a":{"b":[1,2]},"c":"<x>"}` {
+		t.Fatalf("source modified: %s", doc)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+		err   bool
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, false},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, false},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, false},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, false},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, false},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, false},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`, false},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ``, true},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`, false},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ``, true},
+		{`{"foo":null}`, `[{"op":"copy","from":"/foo","path":"/bar"}]`, `{"bar":null,"foo":null}`, false},
+		{`["a"]`, `[{"op":"add","path":"/-","value":["b"]}]`, `["a",["b"]]`, false},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, ``, true},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, ``, true},
+	}
+	for i, test := range tests {
+		patch, err := ParseJSONPatch([]byte(test.patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := JSON(test.doc).ApplyPatch(patch)
+		if (err != nil) != test.err {
+			t.Fatalf("case %d: error %v, want error %t", i, err, test.err)
+		}
+		if err == nil && string(got) != test.want {
+			t.Fatalf("case %d: got %s, want %s", i, got, test.want)
+		}
+	}
+}
+
+func TestJSON_MergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, test := range tests {
+		got, err := JSON(test.doc).MergePatch(JSON(test.patch))
+		if err != nil || string(got) != test.want {
+			t.Fatalf("%s + %s: got %s %v, want %s", test.doc, test.patch, got, err, test.want)
+		}
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		from, to string
+		merged   string // null members cannot be represented by merge patch
+	}{
+		{`{"a":1,"b":[1,2,3],"c":{"d":"e"}}`, `{"a":1.0,"b":[1,4],"c":{"f":null},"g":true}`, `{"a":1,"b":[1,4],"c":{},"g":true}`},
+		{`[1,2]`, `[1,2,3,{"x":"/~"}]`, ""},
+		{`{"a~b":{"c/d":1}}`, `{"a~b":{"c/d":2}}`, ""},
+		{`"x"`, `{"y":1}`, ""},
+		{`{"a":1}`, `{"a":1}`, ""},
+	}
+	for _, test := range tests {
+		patch, err := DiffJSON(JSON(test.from), JSON(test.to))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := JSON(test.from).ApplyPatch(patch)
+		if err != nil {
+			t.Fatalf("%s -> %s: %v, patch:%s", test.from, test.to, err, MustJsonString(patch))
+		}
+		want, _ := decodeJSONValue(JSON(test.to))
+		gv, _ := decodeJSONValue(got)
+		if !equalJSONValue(gv, want) {
+			t.Fatalf("%s -> %s: got %s, patch:%s", test.from, test.to, got, MustJsonString(patch))
+		}
+
+		merge, err := CreateMergePatch(JSON(test.from), JSON(test.to))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err = JSON(test.from).MergePatch(merge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ = decodeJSONValue(JSON(IF(test.merged != "", test.merged, test.to)))
+		gv, _ = decodeJSONValue(got)
+		if !equalJSONValue(gv, want) {
+			t.Fatalf("merge %s -> %s: got %s, patch:%s", test.from, test.to, got, merge)
+		}
+	}
+}
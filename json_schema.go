@@ -0,0 +1,434 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var ErrInvalidJSONSchema = errors.New("tools: invalid json schema")
+
+const (
+	jsonSchemaMaxDepth         = 256   // nesting of $ref/allOf/anyOf/oneOf/not at one instance location
+	jsonSchemaMaxInstanceDepth = 10000 // nesting of the instance, same as encoding/json
+)
+
+// JSONSchemaError one failure of validation, Path is the JSON Pointer of the instance value
+type JSONSchemaError struct {
+	Path    string
+	Keyword string
+	Message string
+}
+
+func (e *JSONSchemaError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", IF(e.Path == "", "/", e.Path), e.Keyword, e.Message)
+}
+
+// JSONSchemaErrors all failures of a validation
+type JSONSchemaErrors []*JSONSchemaError
+
+func (es JSONSchemaErrors) Error() string {
+	msgs := SsToTs(func(e *JSONSchemaError) string { return e.Error() }, es...)
+	return "tools: json schema validation failed: " + strings.Join(msgs, "; ")
+}
+
+// JSONSchema validator of a JSON Schema draft 2020-12 subset:
+// type, enum, const, properties, required, additionalProperties, patternProperties,
+// minProperties, maxProperties, items, prefixItems, minItems, maxItems, uniqueItems,
+// minLength, maxLength, pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+// multipleOf, allOf, anyOf, oneOf, not, $ref (to "#" or "#/json/pointer" of the same document)
+// and $defs. Other keywords are ignored. A JSONSchema can be used concurrently.
+type JSONSchema struct {
+	root     any
+	patterns sync.Map // string -> *regexp.Regexp
+}
+
+func CompileJSONSchema(schema []byte) (*JSONSchema, error) {
+	root, err := decodeJSONValue(schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJSONSchema, err)
+	}
+	s := &JSONSchema{root: root}
+	if err = s.check(root, JSONPointer{}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func MustCompileJSONSchema(schema string) *JSONSchema {
+	s, err := CompileJSONSchema([]byte(schema))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// check verifies the structure of the schema and compiles patterns and references
+func (s *JSONSchema) check(node any, at JSONPointer) error {
+	if _, ok := node.(bool); ok {
+		return nil
+	}
+	m, ok := node.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: %s must be an object or a boolean", ErrInvalidJSONSchema, at)
+	}
+	for k, v := range m {
+		switch k {
+		case "pattern":
+			p, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("%w: %s must be a string", ErrInvalidJSONSchema, at.Append(k))
+			}
+			if _, err := s.regexp(p); err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrInvalidJSONSchema, at.Append(k), err)
+			}
+		case "$ref":
+			ref, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("%w: %s must be a string", ErrInvalidJSONSchema, at.Append(k))
+			}
+			if _, err := s.resolve(ref); err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrInvalidJSONSchema, at.Append(k), err)
+			}
+		case "items", "additionalProperties", "not":
+			if err := s.check(v, at.Append(k)); err != nil {
+				return err
+			}
+		case "properties", "patternProperties", "$defs":
+			sub, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%w: %s must be an object", ErrInvalidJSONSchema, at.Append(k))
+			}
+			for name, ss := range sub {
+				if k == "patternProperties" {
+					if _, err := s.regexp(name); err != nil {
+						return fmt.Errorf("%w: %s: %w", ErrInvalidJSONSchema, at.Append(k, name), err)
+					}
+				}
+				if err := s.check(ss, at.Append(k, name)); err != nil {
+					return err
+				}
+			}
+		case "allOf", "anyOf", "oneOf", "prefixItems":
+			subs, ok := v.([]any)
+			if !ok || (len(subs) == 0 && k != "prefixItems") {
+				return fmt.Errorf("%w: %s must be a non-empty array", ErrInvalidJSONSchema, at.Append(k))
+			}
+			for i, ss := range subs {
+				if err := s.check(ss, at.Append(k, strconv.Itoa(i))); err != nil {
+					return err
+				}
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+			"minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties":
+			if _, ok := v.(json.Number); !ok {
+				return fmt.Errorf("%w: %s must be a number", ErrInvalidJSONSchema, at.Append(k))
+			}
+		}
+	}
+	return nil
+}
+
+func (s *JSONSchema) regexp(pattern string) (*regexp.Regexp, error) {
+	if r, ok := s.patterns.Load(pattern); ok {
+		return r.(*regexp.Regexp), nil
+	}
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns.Store(pattern, r)
+	return r, nil
+}
+
+func (s *JSONSchema) resolve(ref string) (any, error) {
+	if ref == "#" {
+		return s.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	p, err := ParseJSONPointer(ref[1:])
+	if err != nil {
+		return nil, err
+	}
+	return p.get(s.root)
+}
+
+// Validate validates the decoded json value, returns JSONSchemaErrors if there's any failure
+func (s *JSONSchema) Validate(data []byte) error {
+	v, err := decodeJSONValue(data)
+	if err != nil {
+		return err
+	}
+	var errs JSONSchemaErrors
+	s.validate(s.root, v, JSONPointer{}, 0, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateObj validates the JSON encoding of obj
+func (s *JSONSchema) ValidateObj(obj any) error {
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return s.Validate(bs)
+}
+
+// Validator returns a function to be used by RegisterJSONValidator
+func (s *JSONSchema) Validator() func(JSON) error {
+	return func(j JSON) error { return s.Validate(j) }
+}
+
+func (s *JSONSchema) isValid(schema, v any, at JSONPointer, depth int) bool {
+	var errs JSONSchemaErrors
+	s.validate(schema, v, at, depth, &errs)
+	return len(errs) == 0
+}
+
+func jsonTypeOf(v any) string {
+	switch c := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if d, err := ParseDecimal(string(c)); err == nil && d.IsInteger() {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func jsonSchemaInt(v any) int {
+	n, _ := v.(json.Number).Int64()
+	return int(n)
+}
+
+func (s *JSONSchema) validate(schema, v any, at JSONPointer, depth int, errs *JSONSchemaErrors) {
+	fail := func(keyword, format string, args ...any) {
+		*errs = append(*errs, &JSONSchemaError{Path: at.String(), Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+	if len(at) > jsonSchemaMaxInstanceDepth {
+		fail("depth", "max depth exceeded")
+		return
+	}
+	if depth > jsonSchemaMaxDepth {
+		fail("$ref", "too deep")
+		return
+	}
+	m, ok := schema.(map[string]any)
+	if !ok {
+		if b, isBool := schema.(bool); isBool && !b {
+			fail("false", "no value is allowed")
+		}
+		return
+	}
+
+	if ref, ok := m["$ref"].(string); ok {
+		if target, err := s.resolve(ref); err == nil {
+			s.validate(target, v, at, depth+1, errs)
+		}
+	}
+
+	if t, ok := m["type"]; ok {
+		actual := jsonTypeOf(v)
+		var types []string
+		switch tt := t.(type) {
+		case string:
+			types = []string{tt}
+		case []any:
+			types = TsToSs(func(a any) (string, bool) { s, ok := a.(string); return s, ok }, tt...)
+		}
+		matched := false
+		for _, typ := range types {
+			if typ == actual || (typ == "number" && actual == "integer") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("type", "expected %s, but got %s", strings.Join(types, " or "), actual)
+		}
+	}
+	if enum, ok := m["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if equalJSONValue(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("enum", "value is not one of %s", MustJsonString(enum))
+		}
+	}
+	if c, ok := m["const"]; ok && !equalJSONValue(c, v) {
+		fail("const", "value must be %s", MustJsonString(c))
+	}
+
+	switch c := v.(type) {
+	case string:
+		length := utf8.RuneCountInString(c)
+		if n, ok := m["minLength"]; ok && length < jsonSchemaInt(n) {
+			fail("minLength", "length %d is less than %s", length, n)
+		}
+		if n, ok := m["maxLength"]; ok && length > jsonSchemaInt(n) {
+			fail("maxLength", "length %d is greater than %s", length, n)
+		}
+		if p, ok := m["pattern"].(string); ok {
+			if r, err := s.regexp(p); err == nil && !r.MatchString(c) {
+				fail("pattern", "%q does not match %q", c, p)
+			}
+		}
+	case json.Number:
+		d, err := ParseDecimal(string(c))
+		if err != nil {
+			fail("type", "invalid number %s", c)
+			break
+		}
+		limit := func(keyword string, ok func(cmp int) bool, op string) {
+			if n, exist := m[keyword].(json.Number); exist {
+				if l, err := ParseDecimal(string(n)); err == nil && !ok(d.Cmp(l)) {
+					fail(keyword, "%s must be %s %s", c, op, n)
+				}
+			}
+		}
+		limit("minimum", func(cmp int) bool { return cmp >= 0 }, ">=")
+		limit("maximum", func(cmp int) bool { return cmp <= 0 }, "<=")
+		limit("exclusiveMinimum", func(cmp int) bool { return cmp > 0 }, ">")
+		limit("exclusiveMaximum", func(cmp int) bool { return cmp < 0 }, "<")
+		if n, ok := m["multipleOf"].(json.Number); ok {
+			if l, err := ParseDecimal(string(n)); err == nil && !l.IsZero() {
+				if q, err := d.Div(l, 0, RoundDown); err == nil && !q.Mul(l).Equal(d) {
+					fail("multipleOf", "%s is not a multiple of %s", c, n)
+				}
+			}
+		}
+	case []any:
+		if n, ok := m["minItems"]; ok && len(c) < jsonSchemaInt(n) {
+			fail("minItems", "%d items is less than %s", len(c), n)
+		}
+		if n, ok := m["maxItems"]; ok && len(c) > jsonSchemaInt(n) {
+			fail("maxItems", "%d items is greater than %s", len(c), n)
+		}
+		if unique, _ := m["uniqueItems"].(bool); unique {
+		outer:
+			for i := 0; i < len(c); i++ {
+				for j := i + 1; j < len(c); j++ {
+					if equalJSONValue(c[i], c[j]) {
+						fail("uniqueItems", "items %d and %d are equal", i, j)
+						break outer
+					}
+				}
+			}
+		}
+		prefix, _ := m["prefixItems"].([]any)
+		for i, item := range c {
+			if i < len(prefix) {
+				s.validate(prefix[i], item, at.Append(strconv.Itoa(i)), 0, errs)
+			} else if items, ok := m["items"]; ok {
+				s.validate(items, item, at.Append(strconv.Itoa(i)), 0, errs)
+			}
+		}
+	case map[string]any:
+		if n, ok := m["minProperties"]; ok && len(c) < jsonSchemaInt(n) {
+			fail("minProperties", "%d properties is less than %s", len(c), n)
+		}
+		if n, ok := m["maxProperties"]; ok && len(c) > jsonSchemaInt(n) {
+			fail("maxProperties", "%d properties is greater than %s", len(c), n)
+		}
+		if required, ok := m["required"].([]any); ok {
+			for _, r := range required {
+				if name, ok := r.(string); ok {
+					if _, exist := c[name]; !exist {
+						fail("required", "missing property %q", name)
+					}
+				}
+			}
+		}
+		props, _ := m["properties"].(map[string]any)
+		patternProps, _ := m["patternProperties"].(map[string]any)
+		additional, hasAdditional := m["additionalProperties"]
+		for name, pv := range c {
+			matched := false
+			if ps, ok := props[name]; ok {
+				matched = true
+				s.validate(ps, pv, at.Append(name), 0, errs)
+			}
+			for p, ps := range patternProps {
+				if r, err := s.regexp(p); err == nil && r.MatchString(name) {
+					matched = true
+					s.validate(ps, pv, at.Append(name), 0, errs)
+				}
+			}
+			if !matched && hasAdditional {
+				s.validate(additional, pv, at.Append(name), 0, errs)
+			}
+		}
+	}
+
+	if all, ok := m["allOf"].([]any); ok {
+		for _, sub := range all {
+			s.validate(sub, v, at, depth+1, errs)
+		}
+	}
+	if anyOf, ok := m["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if s.isValid(sub, v, at, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("anyOf", "value does not match any schema")
+		}
+	}
+	if oneOf, ok := m["oneOf"].([]any); ok {
+		count := 0
+		for _, sub := range oneOf {
+			if s.isValid(sub, v, at, depth+1) {
+				count++
+			}
+		}
+		if count != 1 {
+			fail("oneOf", "value matches %d schemas, exactly 1 is required", count)
+		}
+	}
+	if not, ok := m["not"]; ok && s.isValid(not, v, at, depth+1) {
+		fail("not", "value must not match the schema")
+	}
+}
+
+// ValidateSchema validates j against the schema, a null JSON is validated as JSON null
+func (j JSON) ValidateSchema(schema *JSONSchema) error {
+	if j.IsNull() {
+		return schema.Validate([]byte("null"))
+	}
+	return schema.Validate(j)
+}
+
+func (n NotNullJSON) ValidateSchema(schema *JSONSchema) error {
+	return schema.Validate(n.Bytes())
+}
+
+func (ja JSONArray[T]) ValidateSchema(schema *JSONSchema) error {
+	return schema.ValidateObj([]T(ja))
+}
@@ -0,0 +1,377 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// JSONArrayElements iterates the elements of the top-level JSON array in r one by one without
+// loading the whole document. Each element is compacted. The iteration stops after the first error,
+// which is yielded with a nil JSON. Only whitespace is allowed after the array.
+func JSONArrayElements(r io.Reader) iter.Seq2[JSON, error] {
+	return func(yield func(JSON, error) bool) {
+		dec := json.NewDecoder(r)
+		tok, err := dec.Token()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			yield(nil, fmt.Errorf("tools: top-level json value is not an array but %v", tok))
+			return
+		}
+		for dec.More() {
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				yield(nil, err)
+				return
+			}
+			buf := new(bytes.Buffer)
+			if err = json.Compact(buf, raw); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(buf.Bytes(), nil) {
+				return
+			}
+		}
+		if _, err = dec.Token(); err != nil {
+			yield(nil, err)
+			return
+		}
+		switch _, err = dec.Token(); {
+		case err == nil:
+			yield(nil, errors.New("tools: invalid character after top-level json value"))
+		case err != io.EOF:
+			yield(nil, err)
+		}
+	}
+}
+
+// JSONArrayElementsOf like JSONArrayElements, but decodes each element into T
+func JSONArrayElementsOf[T any](r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for raw, err := range JSONArrayElements(r) {
+			var t T
+			if err == nil {
+				err = json.Unmarshal(raw, &t)
+			}
+			if !yield(t, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// streamJSON copies JSON tokens from r to w, in the indented form like json.Indent if indented or
+// the compact form. Only one token is buffered at a time, so the document can be larger than the
+// memory. Multiple top-level values are separated by newlines.
+func streamJSON(w io.Writer, r io.Reader, indented bool, prefix, indent string) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	bw := bufio.NewWriter(w)
+
+	type level struct {
+		array bool
+		count int
+	}
+	var (
+		stack  []level
+		values int
+		keyed  bool // a key has been written and its value is expected
+	)
+	newline := func(depth int) {
+		if !indented {
+			return
+		}
+		bw.WriteByte('\n')
+		bw.WriteString(prefix)
+		for i := 0; i < depth; i++ {
+			bw.WriteString(indent)
+		}
+	}
+	// beforeValue writes separators before a value or key
+	beforeValue := func() {
+		if keyed {
+			keyed = false
+			return
+		}
+		if len(stack) == 0 {
+			if values > 0 {
+				bw.WriteByte('\n')
+			}
+			values++
+			return
+		}
+		top := &stack[len(stack)-1]
+		if top.count > 0 {
+			bw.WriteByte(',')
+		}
+		top.count++
+		newline(len(stack))
+	}
+	writeValue := func(v any) error {
+		bs, err := encodeJSONValue(v)
+		if err != nil {
+			return err
+		}
+		_, err = bw.Write(bs)
+		return err
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if d, ok := tok.(json.Delim); ok {
+			switch d {
+			case '[', '{':
+				beforeValue()
+				bw.WriteByte(byte(d))
+				stack = append(stack, level{array: d == '['})
+			case ']', '}':
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if top.count > 0 {
+					newline(len(stack))
+				}
+				bw.WriteByte(byte(d))
+			}
+			continue
+		}
+		inObject := len(stack) > 0 && !stack[len(stack)-1].array
+		if s, ok := tok.(string); ok && inObject && !keyed {
+			beforeValue()
+			if err = writeValue(s); err != nil {
+				return err
+			}
+			bw.WriteByte(':')
+			if indented {
+				bw.WriteByte(' ')
+			}
+			keyed = true
+			continue
+		}
+		beforeValue()
+		if err = writeValue(tok); err != nil {
+			return err
+		}
+	}
+	if len(stack) > 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return bw.Flush()
+}
+
+// CompactJSONStream writes the compact form of the JSON document(s) in r to w
+func CompactJSONStream(w io.Writer, r io.Reader) error {
+	return streamJSON(w, r, false, "", "")
+}
+
+// IndentJSONStream writes the indented form of the JSON document(s) in r to w, like json.Indent
+func IndentJSONStream(w io.Writer, r io.Reader, prefix, indent string) error {
+	return streamJSON(w, r, true, prefix, indent)
+}
+
+// NDJSONLineError an error at a line (starts from 1) of a NDJSON (JSON Lines) document
+type NDJSONLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *NDJSONLineError) Error() string {
+	return fmt.Sprintf("tools: ndjson line %d: %v", e.Line, e.Err)
+}
+
+func (e *NDJSONLineError) Unwrap() error { return e.Err }
+
+// ndjsonLines iterates the non-blank lines of r with their line numbers (starts from 1)
+func ndjsonLines(r io.Reader, maxLineSize []int, yield func(line int, bs []byte, err error) bool) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, VariadicParam(maxLineSize, 1<<20))
+	line := 0
+	for scanner.Scan() {
+		line++
+		bs := bytes.TrimSpace(scanner.Bytes())
+		if len(bs) == 0 {
+			continue
+		}
+		if !yield(line, bs, nil) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		yield(line+1, nil, err)
+	}
+}
+
+// NDJSONLines iterates the lines of a NDJSON (JSON Lines) document, blank lines are skipped. A line
+// which is not valid JSON is yielded with a *NDJSONLineError and the iteration goes on, while a read
+// error stops the iteration. Lines can be up to maxLineSize bytes (1MB by default).
+func NDJSONLines(r io.Reader, maxLineSize ...int) iter.Seq2[JSON, error] {
+	return func(yield func(JSON, error) bool) {
+		ndjsonLines(r, maxLineSize, func(line int, bs []byte, err error) bool {
+			if err != nil {
+				return yield(nil, &NDJSONLineError{Line: line, Err: err})
+			}
+			buf := new(bytes.Buffer)
+			if err = json.Compact(buf, bs); err != nil {
+				return yield(nil, &NDJSONLineError{Line: line, Err: err})
+			}
+			return yield(buf.Bytes(), nil)
+		})
+	}
+}
+
+// NDJSONLinesOf like NDJSONLines, but decodes each line into T. Decoding errors are reported as
+// *NDJSONLineError and the iteration goes on.
+func NDJSONLinesOf[T any](r io.Reader, maxLineSize ...int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ndjsonLines(r, maxLineSize, func(line int, bs []byte, err error) bool {
+			var t T
+			if err == nil {
+				err = json.Unmarshal(bs, &t)
+			}
+			if err != nil {
+				return yield(t, &NDJSONLineError{Line: line, Err: err})
+			}
+			return yield(t, nil)
+		})
+	}
+}
+
+// NDJSONWriter writes values as NDJSON (JSON Lines), one compact JSON value per line
+type NDJSONWriter struct {
+	w     *bufio.Writer
+	enc   *json.Encoder
+	lines int
+}
+
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	return &NDJSONWriter{w: bw, enc: enc}
+}
+
+// Write writes one value, JSON values are compacted and written directly
+func (n *NDJSONWriter) Write(v any) error {
+	var err error
+	switch j := v.(type) {
+	case JSON:
+		err = n.writeRaw(j)
+	case NotNullJSON:
+		err = n.writeRaw(j.Bytes())
+	case json.RawMessage:
+		err = n.writeRaw(j)
+	default:
+		err = n.enc.Encode(v)
+	}
+	if err != nil {
+		return &NDJSONLineError{Line: n.lines + 1, Err: err}
+	}
+	n.lines++
+	return nil
+}
+
+func (n *NDJSONWriter) writeRaw(bs []byte) error {
+	if len(bs) == 0 {
+		bs = []byte("null")
+	}
+	buf := new(bytes.Buffer)
+	if err := json.Compact(buf, bs); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := n.w.Write(buf.Bytes())
+	return err
+}
+
+// Lines number of lines written
+func (n *NDJSONWriter) Lines() int { return n.lines }
+
+func (n *NDJSONWriter) Flush() error { return n.w.Flush() }
+
+// WriteNDJSON writes all values of seq to w as NDJSON, and returns the number of lines written
+func WriteNDJSON[T any](w io.Writer, seq iter.Seq[T]) (int, error) {
+	nw := NewNDJSONWriter(w)
+	for v := range seq {
+		if err := nw.Write(v); err != nil {
+			_ = nw.Flush()
+			return nw.Lines(), err
+		}
+	}
+	return nw.Lines(), nw.Flush()
+}
+
+// ErrJSONArrayWriterClosed is returned when writing to a closed JSONArrayWriter
+var ErrJSONArrayWriterClosed = errors.New("tools: json array writer closed")
+
+// JSONArrayWriter writes a large JSON array element by element
+type JSONArrayWriter struct {
+	w      *bufio.Writer
+	count  int
+	closed bool
+}
+
+func NewJSONArrayWriter(w io.Writer) *JSONArrayWriter {
+	return &JSONArrayWriter{w: bufio.NewWriter(w)}
+}
+
+func (a *JSONArrayWriter) Write(v any) error {
+	if a.closed {
+		return ErrJSONArrayWriterClosed
+	}
+	bs, err := toJSONBytes(v)
+	if err != nil {
+		return err
+	}
+	a.w.WriteByte(IF[byte](a.count == 0, '[', ','))
+	a.count++
+	_, err = a.w.Write(bs)
+	return err
+}
+
+// Close writes the end of the array and flushes, it does not close the underlying writer
+func (a *JSONArrayWriter) Close() error {
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	if a.count == 0 {
+		a.w.WriteByte('[')
+	}
+	a.w.WriteByte(']')
+	return a.w.Flush()
+}
+
+func toJSONBytes(v any) ([]byte, error) {
+	var bs []byte
+	switch j := v.(type) {
+	case JSON:
+		bs = j
+	case NotNullJSON:
+		bs = j.Bytes()
+	case json.RawMessage:
+		bs = j
+	default:
+		return encodeJSONValue(v)
+	}
+	if len(bs) == 0 {
+		return []byte("null"), nil
+	}
+	buf := new(bytes.Buffer)
+	if err := json.Compact(buf, bs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
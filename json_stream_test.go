@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestJSONArrayElements(t *testing.T) {
+	var got []string
+	for e, err := range JSONArrayElements(strings.NewReader(` [ 1, {"a": [ 2 , "<x>" ]}, "s", null ] `)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(e))
+	}
+	if want := []string{`1`, `{"a":[2,"<x>"]}`, `"s"`, `null`}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	type item struct {
+		ID int `json:"id"`
+	}
+	var ids []int
+	for it, err := range JSONArrayElementsOf[item](strings.NewReader(`[{"id":1},{"id":2}]`)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, it.ID)
+	}
+	if !slices.Equal(ids, []int{1, 2}) {
+		t.Fatalf("ids: %v", ids)
+	}
+
+	for _, bad := range []string{`{"a":1}`, `[1,2`, `[1,}`, ``, `[1,2] garbage`, `[1] [2]`} {
+		var err error
+		for _, err = range JSONArrayElements(strings.NewReader(bad)) {
+		}
+		if err == nil {
+			t.Fatalf("%q should fail", bad)
+		}
+	}
+}
+
+func TestJSONStreamFormat(t *testing.T) {
+	src := ` {"a" : [1, 2.50, {}], "b":{"c":"<&>" ,"d":[]} , "e":null}  [true]`
+	buf := new(bytes.Buffer)
+	if err := CompactJSONStream(buf, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"a\":[1,2.50,{}],\"b\":{\"c\":\"<&>\",\"d\":[]},\"e\":null}\n[true]"; buf.String() != want {
+		t.Fatalf("compact: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := IndentJSONStream(buf, strings.NewReader(`{"a":[1,{"b":2}],"c":{}}`), "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"a\": [\n    1,\n    {\n      \"b\": 2\n    }\n  ],\n  \"c\": {}\n}"
+	if buf.String() != want {
+		t.Fatalf("indent:\n%s", buf.String())
+	}
+
+	// an empty indent is kept like json.Indent
+	for _, prefix := range []string{"", "> "} {
+		in := `{"a":[1,{"b":2}],"c":{}}`
+		buf.Reset()
+		if err := IndentJSONStream(buf, strings.NewReader(in), prefix, ""); err != nil {
+			t.Fatal(err)
+		}
+		std := new(bytes.Buffer)
+		_ = json.Indent(std, []byte(in), prefix, "")
+		if buf.String() != std.String() {
+			t.Fatalf("empty indent: %q, want %q", buf.String(), std.String())
+		}
+	}
+
+	if err := CompactJSONStream(new(bytes.Buffer), strings.NewReader(`{"a":[1,2}`)); err == nil {
+		t.Fatal("invalid json should fail")
+	}
+	if err := CompactJSONStream(new(bytes.Buffer), strings.NewReader(`{"a":[1,2]`)); err == nil {
+		t.Fatal("truncated json should fail")
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	src := "{\"id\":1}\n\n{\"id\": 2}\nbad\n{\"id\":\"x\"}\n"
+	var got []string
+	var lineErrs []int
+	for j, err := range NDJSONLines(strings.NewReader(src)) {
+		var le *NDJSONLineError
+		if errors.As(err, &le) {
+			lineErrs = append(lineErrs, le.Line)
+			continue
+		}
+		got = append(got, string(j))
+	}
+	if !slices.Equal(got, []string{`{"id":1}`, `{"id":2}`, `{"id":"x"}`}) || !slices.Equal(lineErrs, []int{4}) {
+		t.Fatalf("lines: %v, errors at %v", got, lineErrs)
+	}
+
+	type item struct {
+		ID int `json:"id"`
+	}
+	var ids []int
+	lineErrs = nil
+	for it, err := range NDJSONLinesOf[item](strings.NewReader(src)) {
+		var le *NDJSONLineError
+		if errors.As(err, &le) {
+			lineErrs = append(lineErrs, le.Line)
+			continue
+		}
+		ids = append(ids, it.ID)
+	}
+	if !slices.Equal(ids, []int{1, 2}) || !slices.Equal(lineErrs, []int{4, 5}) {
+		t.Fatalf("ids: %v, errors at %v", ids, lineErrs)
+	}
+
+	buf := new(bytes.Buffer)
+	n, err := WriteNDJSON(buf, slices.Values([]any{item{ID: 1}, JSON(`{ "a" : "<b>" }`), nil}))
+	if err != nil || n != 3 || buf.String() != "{\"id\":1}\n{\"a\":\"<b>\"}\nnull\n" {
+		t.Fatalf("write: %d %v %q", n, err, buf.String())
+	}
+	if _, err = WriteNDJSON(buf, slices.Values([]JSON{JSON(`{`)})); err == nil {
+		t.Fatal("invalid json should fail")
+	}
+}
+
+func TestJSONArrayWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewJSONArrayWriter(buf)
+	if err := w.Close(); err != nil || buf.String() != "[]" {
+		t.Fatalf("empty: %s %v", buf.String(), err)
+	}
+	buf.Reset()
+	w = NewJSONArrayWriter(buf)
+	for _, v := range []any{1, "a&b", JSON(`{ "k": [1] }`)} {
+		if err := w.Write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil || buf.String() != `[1,"a&b",{"k":[1]}]` {
+		t.Fatalf("array: %s %v", buf.String(), err)
+	}
+	if err := w.Write(2); !errors.Is(err, ErrJSONArrayWriterClosed) {
+		t.Fatalf("write after close: %v", err)
+	}
+}
@@ -237,3 +237,14 @@ func (l *List[T]) All() iter.Seq[T] {
 		}
 	}
 }
+
+// Backward iterates from the back to the front
+func (l *List[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
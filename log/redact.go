@@ -0,0 +1,498 @@
+package log
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stephenfire/go-tools/internal/orderedjson"
+)
+
+const (
+	// RedactTag struct tag of field redaction: `redact:"true"` always masks the field, while
+	// `redact:"false"` stops masking the field by its name.
+	RedactTag   = "redact"
+	DefaultMask = "******"
+
+	redactMaxDepth = 32
+)
+
+var (
+	// DefaultRedactKeys key name patterns of DefaultRedactor. Keys and patterns are split into words
+	// by '_', '-', '.' and camel case and compared case insensitively, a key matches if it contains
+	// the words of a pattern: "id_card" matches "idCard", "ID-Card" and "user_id_card_no", but not
+	// "idcardType".
+	DefaultRedactKeys = []string{"password", "passwd", "secret", "token", "authorization", "id_card", "phone", "mobile"}
+	// DefaultRedactExceptions keys never masked by their names although they match the key patterns,
+	// compared as whole keys in the same way.
+	DefaultRedactExceptions = []string{"token_count", "token_type", "phone_verified", "mobile_verified"}
+	DefaultRedactor         = NewRedactor()
+
+	// emails, or alphanumeric words which are checked as mobile or ID card numbers
+	redactValueRegexp = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}|[A-Za-z0-9]+`)
+	mobileRegexp      = regexp.MustCompile(`^1[3-9][0-9]{9}$`)
+	idCardRegexp      = regexp.MustCompile(`^[1-9][0-9]{16}[0-9Xx]$`)
+	idCardWeights     = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+)
+
+// Redactor masks sensitive data in values before they are marshalled or logged:
+//  1. fields tagged with `redact:"true"`
+//  2. members of objects and fields whose names match the key patterns
+//  3. Chinese mobile numbers, ID card numbers and emails in strings (partly masked)
+type Redactor struct {
+	// Mask replaces the whole value of a sensitive field, DefaultMask if empty
+	Mask string
+	// KeepValues disables masking of mobile numbers, ID card numbers and emails in strings
+	KeepValues bool
+	keys       [][]string
+	exceptions [][]string
+}
+
+// NewRedactor creates a Redactor with key name patterns, DefaultRedactKeys is used if no pattern
+// specified. DefaultRedactExceptions are always excepted.
+func NewRedactor(keyPatterns ...string) *Redactor {
+	if len(keyPatterns) == 0 {
+		keyPatterns = DefaultRedactKeys
+	}
+	r := &Redactor{}
+	for _, p := range keyPatterns {
+		if words := redactKeyWords(p); len(words) > 0 {
+			r.keys = append(r.keys, words)
+		}
+	}
+	return r.Except(DefaultRedactExceptions...)
+}
+
+// Except adds keys which are not masked by their names, returns r
+func (r *Redactor) Except(keys ...string) *Redactor {
+	for _, k := range keys {
+		if words := redactKeyWords(k); len(words) > 0 {
+			r.exceptions = append(r.exceptions, words)
+		}
+	}
+	return r
+}
+
+// redactKeyWords splits key into lower case words by '_', '-', '.', spaces and camel case, e.g.
+// "userIDCard" to [user id card]
+func redactKeyWords(key string) []string {
+	var words []string
+	start := -1
+	for i := 0; i <= len(key); i++ {
+		if i == len(key) || !isAlnum(key[i]) {
+			if start >= 0 {
+				words = append(words, strings.ToLower(key[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		// a new word starts at an upper case letter after a lower case one, or before a lower case
+		// one in an acronym like "IDCard"
+		if isUpper(key[i]) && (!isUpper(key[i-1]) || (i+1 < len(key) && isLower(key[i+1]))) {
+			words = append(words, strings.ToLower(key[start:i]))
+			start = i
+		}
+	}
+	return words
+}
+
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
+func isLower(c byte) bool { return c >= 'a' && c <= 'z' }
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+func isAlnum(c byte) bool { return isUpper(c) || isLower(c) || isDigit(c) }
+
+func (r *Redactor) mask() string {
+	if r.Mask == "" {
+		return DefaultMask
+	}
+	return r.Mask
+}
+
+// SensitiveKey reports whether the key name contains the words of any key pattern and is not an
+// exception, see DefaultRedactKeys
+func (r *Redactor) SensitiveKey(key string) bool {
+	words := redactKeyWords(key)
+	for _, e := range r.exceptions {
+		if slices.Equal(words, e) {
+			return false
+		}
+	}
+	for _, p := range r.keys {
+		for i := 0; i+len(p) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(p)], p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isIDCard checks the birth date and the check digit of a Chinese ID card number
+func isIDCard(s string) bool {
+	if !idCardRegexp.MatchString(s) {
+		return false
+	}
+	birth, err := time.Parse("20060102", s[6:14])
+	if err != nil || birth.Year() < 1900 || birth.After(time.Now()) {
+		return false
+	}
+	sum := 0
+	for i, w := range idCardWeights {
+		sum += int(s[i]-'0') * w
+	}
+	check := s[17]
+	if check == 'x' {
+		check = 'X'
+	}
+	return "10X98765432"[sum%11] == check
+}
+
+// MaskString partly masks mobile numbers (138****5678), ID card numbers (110101********109X) and
+// emails (a***@example.com) in s. Numbers must be whole words, and ID card numbers must have a
+// valid birth date and check digit, so that numbers like order IDs are kept.
+func (r *Redactor) MaskString(s string) string {
+	if r.KeepValues || s == "" {
+		return s
+	}
+	return redactValueRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		switch {
+		case strings.IndexByte(m, '@') > 0:
+			local, domain, _ := strings.Cut(m, "@")
+			return local[:1] + "***@" + domain
+		case mobileRegexp.MatchString(m):
+			return m[:3] + "****" + m[7:]
+		case isIDCard(m):
+			return m[:6] + "********" + m[14:]
+		}
+		return m
+	})
+}
+
+// Redact returns a redacted copy of v. Strings are masked by MaskString, structs are converted to
+// objects which keep the declaration order of fields when marshalled, maps and slices to
+// map[string]any and []any in the way of encoding/json, and json.Marshaler values are marshalled and
+// redacted as JSON. v is not modified.
+func (r *Redactor) Redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	return r.redactValue(reflect.ValueOf(v), 0)
+}
+
+// RedactJSON returns the redacted JSON of data, the order of object members is kept
+func (r *Redactor) RedactJSON(data []byte) ([]byte, error) {
+	v, err := decodeRedactJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return encodeRedactJSON(r.redactDecoded(v, 0))
+}
+
+// RedactFields returns a redacted copy of the fields of a log entry
+func (r *Redactor) RedactFields(fields logrus.Fields) logrus.Fields {
+	if fields == nil {
+		return nil
+	}
+	ret := make(logrus.Fields, len(fields))
+	for k, v := range fields {
+		if r.SensitiveKey(k) && v != nil {
+			ret[k] = r.mask()
+		} else {
+			ret[k] = r.Redact(v)
+		}
+	}
+	return ret
+}
+
+func decodeRedactJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return orderedjson.Decode(dec)
+}
+
+func encodeRedactJSON(v any) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// redactDecoded redacts a value decoded from JSON
+func (r *Redactor) redactDecoded(v any, depth int) any {
+	switch c := v.(type) {
+	case string:
+		return r.MaskString(c)
+	case orderedjson.Members:
+		ret := make(orderedjson.Members, len(c))
+		for i, m := range c {
+			ret[i].Key = m.Key
+			if m.Value != nil && r.SensitiveKey(m.Key) {
+				ret[i].Value = r.mask()
+			} else {
+				ret[i].Value = r.redactDecoded(m.Value, depth+1)
+			}
+		}
+		return ret
+	case []any:
+		ret := make([]any, len(c))
+		for i, vv := range c {
+			ret[i] = r.redactDecoded(vv, depth+1)
+		}
+		return ret
+	}
+	return v
+}
+
+func (r *Redactor) redactValue(rv reflect.Value, depth int) any {
+	if !rv.IsValid() {
+		return nil
+	}
+	if depth > redactMaxDepth {
+		return r.mask()
+	}
+	if (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return nil
+	}
+	// values of promoted fields of unexported embedded structs are read only
+	if rv.CanInterface() {
+		if v, ok := r.redactMarshaler(rv, depth); ok {
+			return v
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return r.redactValue(rv.Elem(), depth+1)
+	case reflect.String:
+		return r.MaskString(rv.String())
+	case reflect.Struct:
+		ret := orderedjson.Members{}
+		r.redactStruct(rv, &ret, depth)
+		return ret
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		ret := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := redactKeyString(iter.Key())
+			if r.SensitiveKey(k) && !isNilValue(iter.Value()) {
+				ret[k] = r.mask()
+			} else {
+				ret[k] = r.redactValue(iter.Value(), depth+1)
+			}
+		}
+		return ret
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as base64 string by encoding/json
+			return bytes.Clone(rv.Bytes())
+		}
+		ret := make([]any, rv.Len())
+		for i := range ret {
+			ret[i] = r.redactValue(rv.Index(i), depth+1)
+		}
+		return ret
+	}
+	return basicValue(rv)
+}
+
+func (r *Redactor) redactMarshaler(rv reflect.Value, depth int) (any, bool) {
+	switch m := rv.Interface().(type) {
+	case json.Marshaler:
+		bs, err := m.MarshalJSON()
+		if err != nil {
+			return r.MaskString(fmt.Sprintf("%+v", m)), true
+		}
+		v, err := decodeRedactJSON(bs)
+		if err != nil {
+			return r.MaskString(string(bs)), true
+		}
+		return r.redactDecoded(v, depth), true
+	case encoding.TextMarshaler:
+		bs, err := m.MarshalText()
+		if err != nil {
+			return r.MaskString(fmt.Sprintf("%+v", m)), true
+		}
+		return r.MaskString(string(bs)), true
+	case error:
+		return r.MaskString(m.Error()), true
+	}
+	return nil, false
+}
+
+// basicValue returns the value of rv without Interface(), which panics on read only values
+func basicValue(rv reflect.Value) any {
+	if rv.CanInterface() {
+		switch rv.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			return rv.Interface()
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	}
+	return rv.Type().String()
+}
+
+func redactKeyString(k reflect.Value) string {
+	if k.CanInterface() {
+		return fmt.Sprint(k.Interface())
+	}
+	return fmt.Sprint(basicValue(k))
+}
+
+func (r *Redactor) redactStruct(rv reflect.Value, out *orderedjson.Members, depth int) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := rv.Field(i)
+		if sf.Anonymous && name == "" {
+			// fields of embedded structs are promoted
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() || !sf.IsExported() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				r.redactStruct(fv, out, depth)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyValue(fv) {
+			continue
+		}
+		switch rtag := sf.Tag.Get(RedactTag); {
+		case rtag == "true":
+			out.Set(name, r.mask())
+		case rtag != "false" && r.SensitiveKey(name) && !isNilValue(fv):
+			out.Set(name, r.mask())
+		default:
+			out.Set(name, r.redactValue(fv, depth+1))
+		}
+	}
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return !v.IsValid()
+}
+
+// isEmptyValue the same as omitempty of encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// RedactFormatter redacts the message and fields of log entries before formatting
+type RedactFormatter struct {
+	Formatter logrus.Formatter
+	Redactor  *Redactor
+}
+
+// NewRedactFormatter wraps formatter, DefaultRedactor is used if redactor is nil
+func NewRedactFormatter(formatter logrus.Formatter, redactor *Redactor) *RedactFormatter {
+	if formatter == nil {
+		formatter = &logrus.TextFormatter{}
+	}
+	if redactor == nil {
+		redactor = DefaultRedactor
+	}
+	return &RedactFormatter{Formatter: formatter, Redactor: redactor}
+}
+
+func (f *RedactFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	e := *entry
+	e.Data = f.Redactor.RedactFields(entry.Data)
+	e.Message = f.Redactor.MaskString(entry.Message)
+	return f.Formatter.Format(&e)
+}
+
+func redactFormatter(formatter logrus.Formatter, redactor *Redactor) logrus.Formatter {
+	if rf, ok := formatter.(*RedactFormatter); ok {
+		return NewRedactFormatter(rf.Formatter, redactor)
+	}
+	return NewRedactFormatter(formatter, redactor)
+}
+
+// SetFormatter changes the formatter of the hook
+func (m *FileAndConsoleHook) SetFormatter(formatter logrus.Formatter) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.formatter = formatter
+}
+
+// EnableRedaction redacts all logs of the root logger including those written by FileAndConsoleHook,
+// DefaultRedactor is used if redactor is nil. Call it again after InitLogWithSuffix.
+func EnableRedaction(redactor *Redactor) {
+	_logLocker.Lock()
+	defer _logLocker.Unlock()
+
+	rootLog.SetFormatter(redactFormatter(rootLog.Formatter, redactor))
+	seen := make(map[*FileAndConsoleHook]struct{})
+	for _, hooks := range rootLog.Hooks {
+		for _, h := range hooks {
+			fc, ok := h.(*FileAndConsoleHook)
+			if !ok {
+				continue
+			}
+			if _, exist := seen[fc]; exist {
+				continue
+			}
+			seen[fc] = struct{}{}
+			fc.lock.Lock()
+			fc.formatter = redactFormatter(fc.formatter, redactor)
+			fc.lock.Unlock()
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedactor_MaskString(t *testing.T) {
+	r := NewRedactor()
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"call 13812345678 now", "call 138****5678 now"},
+		{"id:11010119900307109X.", "id:110101********109X."},
+		{"110101199003071233", "110101********1233"},
+		{"11010119900307109x", "110101********109x"},
+		{"110101199003071234 110101199013071233 123456789012345678", "110101199003071234 110101199013071233 123456789012345678"},
+		{"a13812345678 13812345678b x_13812345678", "a13812345678 13812345678b x_138****5678"},
+		{"mail Alice.B@example.com, bob@x.cn", "mail A***@example.com, b***@x.cn"},
+		{"order 123456789012 amount 12345678901", "order 123456789012 amount 12345678901"},
+		{"138123456789", "138123456789"},
+	}
+	for _, test := range tests {
+		if got := r.MaskString(test.in); got != test.want {
+			t.Errorf("MaskString(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+	if got := (&Redactor{KeepValues: true}).MaskString("13812345678"); got != "13812345678" {
+		t.Errorf("keep values: %s", got)
+	}
+}
+
+func TestRedactor_SensitiveKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		sensitive bool
+	}{
+		{"password", true},
+		{"Password", true},
+		{"user_password", true},
+		{"password_hash", true},
+		{"accessToken", true},
+		{"X-Auth-Token", true},
+		{"md5Token", true},
+		{"secretKey", true},
+		{"secret_key", true},
+		{"idCard", true},
+		{"ID-Card", true},
+		{"userIDCard", true},
+		{"id_card_no", true},
+		{"idCardNumber", true},
+		{"PHONE", true},
+		{"phone_number", true},
+		{"phoneNo", true},
+		{"mobileNumber", true},
+		{"authorization", true},
+		{"tokenCount", false},
+		{"token_type", false},
+		{"phoneVerified", false},
+		{"idcardType", false},
+		{"passwords_count", false},
+		{"name", false},
+		{"", false},
+		{"__", false},
+	}
+	r := NewRedactor()
+	for _, test := range tests {
+		if got := r.SensitiveKey(test.key); got != test.sensitive {
+			t.Errorf("SensitiveKey(%q) = %t, want %t", test.key, got, test.sensitive)
+		}
+	}
+	if r = NewRedactor().Except("phone_number"); r.SensitiveKey("phoneNumber") || !r.SensitiveKey("phoneNo") {
+		t.Error("except failed")
+	}
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	type base struct {
+		UserID int64 `json:"userId"`
+	}
+	type account struct {
+		base
+		Name      string            `json:"name"`
+		Password  string            `json:"password"`
+		IDCard    string            `json:"id_card,omitempty"`
+		Secret    string            `json:"-"`
+		Note      string            `json:"note" redact:"true"`
+		PhoneType string            `json:"phoneType" redact:"false"`
+		Email     string            `json:"email"`
+		Token     *string           `json:"token"`
+		Extra     map[string]any    `json:"extra"`
+		Raw       json.RawMessage   `json:"raw"`
+		Headers   map[string]string `json:"headers,omitempty"`
+	}
+	a := &account{
+		base:      base{UserID: 9},
+		Name:      "alice",
+		Password:  "p@ss",
+		Note:      "anything",
+		PhoneType: "mobile",
+		Email:     "alice@example.com",
+		Extra:     map[string]any{"accessToken": "abc", "list": []any{"13812345678", 1}},
+		Raw:       json.RawMessage(`{"mobile":"13900001111","x":"bob@y.com"}`),
+	}
+	bs, err := json.Marshal(NewRedactor().Redact(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"userId":9,"name":"alice","password":"******","note":"******","phoneType":"mobile","email":"a***@example.com","token":null,"extra":{"accessToken":"******","list":["138****5678",1]},"raw":{"mobile":"******","x":"b***@y.com"}}`
+	if string(bs) != want {
+		t.Fatalf("got  %s\nwant %s", bs, want)
+	}
+	if a.Password != "p@ss" || a.Extra["accessToken"] != "abc" {
+		t.Fatal("source modified")
+	}
+
+	r := NewRedactor("card_no")
+	r.Mask = "***"
+	got, err := r.RedactJSON([]byte(`{"cardNo":"6222","password":"x","items":[{"Card-No":null}]}`))
+	if err != nil || string(got) != `{"cardNo":"***","password":"x","items":[{"Card-No":null}]}` {
+		t.Fatalf("redact json: %s %v", got, err)
+	}
+}
+
+func TestRedactFormatter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := logrus.New()
+	logger.Out = buf
+	logger.Formatter = NewRedactFormatter(&logrus.JSONFormatter{DisableTimestamp: true}, nil)
+	fields := logrus.Fields{"user": map[string]string{"name": "bob", "pwd_token": "t"}, "phone": "13812345678", "error": errors.New("bad email a@b.com")}
+	logger.WithFields(fields).Info("login 13812345678")
+	out := buf.String()
+	for _, want := range []string{`"msg":"login 138****5678"`, `"phone":"******"`, `"pwd_token":"******"`, `"error":"bad email a***@b.com"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%s not found in %s", want, out)
+		}
+	}
+	if fields["phone"] != "13812345678" {
+		t.Error("fields modified")
+	}
+
+	hookOut := new(bytes.Buffer)
+	hook := NewFileAndConsoleHook(&logrus.TextFormatter{DisableTimestamp: true}, hookOut, nil)
+	_logLocker.Lock()
+	saved := rootLog
+	rootLog = logrus.New()
+	rootLog.Out = new(bytes.Buffer)
+	rootLog.AddHook(hook)
+	_logLocker.Unlock()
+	defer func() {
+		_logLocker.Lock()
+		rootLog, wrapped = saved, saved
+		_logLocker.Unlock()
+	}()
+	EnableRedaction(nil)
+	EnableRedaction(nil)
+	WithField("password", "123456", "x", "y").Info("hello")
+	if s := hookOut.String(); !strings.Contains(s, "password=\"******\"") || strings.Contains(s, "123456") {
+		t.Fatalf("hook output: %s", s)
+	}
+	if rf, ok := rootLog.Formatter.(*RedactFormatter); !ok || rf.Formatter == nil {
+		t.Fatalf("root formatter: %T", rootLog.Formatter)
+	} else if _, nested := rf.Formatter.(*RedactFormatter); nested {
+		t.Fatal("redact formatter wrapped twice")
+	}
+}
@@ -1,10 +1,15 @@
 package tools
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"iter"
 	"maps"
+	"reflect"
 	"slices"
+	"strconv"
 )
 
 type Exister[K comparable] interface {
@@ -420,7 +425,8 @@ func (km KSet[K]) RangeSubSet(batchSize int, ranger func(s KSet[K]) bool) {
 	})
 }
 
-// OrderMap 一个按插入顺序遍历的map，也可以通过给出的排序器顺序遍历。线程不安全。
+// OrderMap 一个按插入顺序遍历的map，也可以通过给出的排序器顺序遍历(Sorted)。线程不安全。
+// 可以通过Set更新或追加，通过MoveToFront/MoveToBack/MoveBefore/MoveAfter调整顺序。
 type OrderMap[K comparable, V any] struct {
 	m map[K]V
 	e map[K]*ListElement[K]
@@ -435,6 +441,7 @@ func NewOrderMap[K comparable, V any]() *OrderMap[K, V] {
 	}
 }
 
+// Put appends k with v if k not exists, the value of an existing key is not changed, see Set
 func (m *OrderMap[K, V]) Put(k K, v V) *OrderMap[K, V] {
 	_, exist := m.m[k]
 	if exist {
@@ -473,6 +480,272 @@ func (m *OrderMap[K, V]) All() iter.Seq2[K, V] {
 	}
 }
 
+// Set updates the value of k in place if k exists, or appends k with v
+func (m *OrderMap[K, V]) Set(k K, v V) *OrderMap[K, V] {
+	if _, exist := m.m[k]; !exist {
+		m.e[k] = m.l.PushBack(k)
+	}
+	m.m[k] = v
+	return m
+}
+
+func (m *OrderMap[K, V]) IsExist(k K) bool {
+	_, exist := m.m[k]
+	return exist
+}
+
+// MoveToFront moves k to the first, returns false if k not exists
+func (m *OrderMap[K, V]) MoveToFront(k K) bool {
+	elem, exist := m.e[k]
+	if exist {
+		m.l.MoveToFront(elem)
+	}
+	return exist
+}
+
+// MoveToBack moves k to the last, returns false if k not exists
+func (m *OrderMap[K, V]) MoveToBack(k K) bool {
+	elem, exist := m.e[k]
+	if exist {
+		m.l.MoveToBack(elem)
+	}
+	return exist
+}
+
+// MoveBefore moves k before mark, returns false if k or mark not exists
+func (m *OrderMap[K, V]) MoveBefore(k, mark K) bool {
+	elem, exist := m.e[k]
+	markElem, markExist := m.e[mark]
+	if !exist || !markExist {
+		return false
+	}
+	m.l.MoveBefore(elem, markElem)
+	return true
+}
+
+// MoveAfter moves k after mark, returns false if k or mark not exists
+func (m *OrderMap[K, V]) MoveAfter(k, mark K) bool {
+	elem, exist := m.e[k]
+	markElem, markExist := m.e[mark]
+	if !exist || !markExist {
+		return false
+	}
+	m.l.MoveAfter(elem, markElem)
+	return true
+}
+
+func (m *OrderMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for k := range m.l.All() {
+			if !yield(m.m[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterates from the last to the first
+func (m *OrderMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k := range m.l.Backward() {
+			if !yield(k, m.m[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Sorted iterates by the order of keys given by less, keys with the same order keep their current
+// order. The order of m is not changed.
+func (m *OrderMap[K, V]) Sorted(less func(a, b K) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		ks := slices.Collect(m.l.All())
+		slices.SortStableFunc(ks, func(a, b K) int {
+			if less(a, b) {
+				return -1
+			}
+			if less(b, a) {
+				return 1
+			}
+			return 0
+		})
+		for _, k := range ks {
+			if !yield(k, m.m[k]) {
+				return
+			}
+		}
+	}
+}
+
+// At returns the key and value at index i (starts from 0, negative index counts from the last),
+// it takes O(n) time.
+func (m *OrderMap[K, V]) At(i int) (k K, v V, ok bool) {
+	n := m.l.Len()
+	if i < 0 {
+		i += n
+	}
+	if i < 0 || i >= n {
+		return k, v, false
+	}
+	var elem *ListElement[K]
+	if i < n/2 {
+		for elem = m.l.Front(); i > 0; i-- {
+			elem = elem.Next()
+		}
+	} else {
+		for elem = m.l.Back(); i < n-1; i++ {
+			elem = elem.Prev()
+		}
+	}
+	return elem.Value, m.m[elem.Value], true
+}
+
+// IndexOf returns the index of k, or -1 if k not exists. It takes O(n) time.
+func (m *OrderMap[K, V]) IndexOf(k K) int {
+	if _, exist := m.e[k]; !exist {
+		return -1
+	}
+	i := 0
+	for key := range m.l.All() {
+		if key == k {
+			return i
+		}
+		i++
+	}
+	return -1
+}
+
+func (m *OrderMap[K, V]) Clone() *OrderMap[K, V] {
+	if m == nil {
+		return nil
+	}
+	ret := NewOrderMap[K, V]()
+	for k, v := range m.All() {
+		ret.Put(k, v)
+	}
+	return ret
+}
+
+// marshalMapKey converts a map key to the JSON object key like encoding/json: string kinds are used
+// directly, encoding.TextMarshaler is marshalled and integers are formatted.
+func marshalMapKey[K comparable](k K) (string, error) {
+	rv := reflect.ValueOf(k)
+	if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	if tm, ok := any(k).(encoding.TextMarshaler); ok {
+		bs, err := tm.MarshalText()
+		return string(bs), err
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("tools: unsupported json object key type %T", k)
+}
+
+// MarshalJSON writes a JSON object in the order of m
+func (m *OrderMap[K, V]) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	i := 0
+	for k, v := range m.All() {
+		ks, err := marshalMapKey(k)
+		if err != nil {
+			return nil, err
+		}
+		kbs, err := json.Marshal(ks)
+		if err != nil {
+			return nil, err
+		}
+		vbs, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(kbs)
+		buf.WriteByte(':')
+		buf.Write(vbs)
+		i++
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unmarshalMapKey converts a JSON object key to K like encoding/json
+func unmarshalMapKey[K comparable](s string) (k K, err error) {
+	rv := reflect.ValueOf(&k).Elem()
+	if rv.Kind() == reflect.String {
+		rv.SetString(s)
+		return k, nil
+	}
+	if tu, ok := any(&k).(encoding.TextUnmarshaler); ok {
+		err = tu.UnmarshalText([]byte(s))
+		return k, err
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return k, fmt.Errorf("tools: invalid json object key %q: %w", s, err)
+		}
+		rv.SetInt(i)
+		return k, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return k, fmt.Errorf("tools: invalid json object key %q: %w", s, err)
+		}
+		rv.SetUint(u)
+		return k, nil
+	}
+	return k, fmt.Errorf("tools: unsupported json object key type %T", k)
+}
+
+// UnmarshalJSON replaces the content of m with a JSON object in document order, the value of a
+// duplicated key is overwritten by the later one and keeps the position of the first one. null
+// leaves m unchanged.
+func (m *OrderMap[K, V]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("tools: OrderMap expects a json object but got %v", tok)
+	}
+	om := NewOrderMap[K, V]()
+	for dec.More() {
+		if tok, err = dec.Token(); err != nil {
+			return err
+		}
+		k, err := unmarshalMapKey[K](tok.(string))
+		if err != nil {
+			return err
+		}
+		var v V
+		if err = dec.Decode(&v); err != nil {
+			return err
+		}
+		om.Set(k, v)
+	}
+	if _, err = dec.Token(); err != nil {
+		return err
+	}
+	*m = *om
+	return nil
+}
+
 func (m *OrderMap[K, V]) selfCheck() error {
 	if len(m.m) != len(m.e) || len(m.m) != m.l.Len() {
 		return fmt.Errorf("length mismatch: m:%d e:%d l:%d", len(m.m), len(m.e), m.l.Len())
@@ -0,0 +1,519 @@
+package tools
+
+import (
+	"hash/maphash"
+	"iter"
+	"slices"
+	"sync"
+)
+
+const DefaultConcurrentShards = 32
+
+type concurrentShard[K comparable, V any] struct {
+	lock sync.RWMutex
+	m    map[K]V
+}
+
+// ConcurrentMap 分片加锁的并发安全map，方法与KMap一致。遍历(All/Keys/Values/RangeSubMap)基于各分片的
+// 快照，遍历过程中不持有锁，因此可以在遍历时修改map，但不保证所有分片的快照是同一时刻的。
+type ConcurrentMap[K comparable, V any] struct {
+	seed   maphash.Seed
+	shards []*concurrentShard[K, V]
+}
+
+// NewConcurrentMap creates a map with shardCount (rounded up to a power of 2) shards,
+// DefaultConcurrentShards by default
+func NewConcurrentMap[K comparable, V any](shardCount ...int) *ConcurrentMap[K, V] {
+	n := 1
+	for n < VariadicParam(shardCount, DefaultConcurrentShards) {
+		n <<= 1
+	}
+	m := &ConcurrentMap[K, V]{seed: maphash.MakeSeed(), shards: make([]*concurrentShard[K, V], n)}
+	for i := range m.shards {
+		m.shards[i] = &concurrentShard[K, V]{m: make(map[K]V)}
+	}
+	return m
+}
+
+func (m *ConcurrentMap[K, V]) shard(k K) *concurrentShard[K, V] {
+	return m.shards[maphash.Comparable(m.seed, k)&uint64(len(m.shards)-1)]
+}
+
+func (m *ConcurrentMap[K, V]) Put(k K, v V) *ConcurrentMap[K, V] {
+	s := m.shard(k)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.m[k] = v
+	return m
+}
+
+func (m *ConcurrentMap[K, V]) Puts(it iter.Seq2[K, V]) *ConcurrentMap[K, V] {
+	for k, v := range it {
+		m.Put(k, v)
+	}
+	return m
+}
+
+func (m *ConcurrentMap[K, V]) Merge(o KMap[K, V]) *ConcurrentMap[K, V] {
+	for k, v := range o {
+		m.Put(k, v)
+	}
+	return m
+}
+
+func (m *ConcurrentMap[K, V]) Get(k K) (v V, exist bool) {
+	s := m.shard(k)
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	v, exist = s.m[k]
+	return
+}
+
+func (m *ConcurrentMap[K, V]) IsExist(k K) bool {
+	_, exist := m.Get(k)
+	return exist
+}
+
+func (m *ConcurrentMap[K, V]) Delete(ks ...K) {
+	for _, k := range ks {
+		s := m.shard(k)
+		s.lock.Lock()
+		delete(s.m, k)
+		s.lock.Unlock()
+	}
+}
+
+// LoadAndDelete deletes k and returns the value before deleting
+func (m *ConcurrentMap[K, V]) LoadAndDelete(k K) (v V, loaded bool) {
+	s := m.shard(k)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	v, loaded = s.m[k]
+	if loaded {
+		delete(s.m, k)
+	}
+	return
+}
+
+func (m *ConcurrentMap[K, V]) Len() int {
+	n := 0
+	for _, s := range m.shards {
+		s.lock.RLock()
+		n += len(s.m)
+		s.lock.RUnlock()
+	}
+	return n
+}
+
+func (m *ConcurrentMap[K, V]) Clear() {
+	for _, s := range m.shards {
+		s.lock.Lock()
+		clear(s.m)
+		s.lock.Unlock()
+	}
+}
+
+// GetOrPut returns the existing value of k if present, otherwise puts v and returns it
+func (m *ConcurrentMap[K, V]) GetOrPut(k K, v V) (actual V, loaded bool) {
+	return m.GetOrCompute(k, func() V { return v })
+}
+
+// GetOrCompute returns the existing value of k if present, otherwise computes, puts and returns the
+// new value. compute is called at most once under the lock of the shard, so it must not access the
+// map itself.
+func (m *ConcurrentMap[K, V]) GetOrCompute(k K, compute func() V) (actual V, loaded bool) {
+	s := m.shard(k)
+	s.lock.RLock()
+	actual, loaded = s.m[k]
+	s.lock.RUnlock()
+	if loaded {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if actual, loaded = s.m[k]; loaded {
+		return
+	}
+	actual = compute()
+	s.m[k] = actual
+	return actual, false
+}
+
+// CompareAndSwap swaps the value of k to nv if the current value equals to old. Like sync.Map, it
+// panics if V is not comparable at runtime.
+func (m *ConcurrentMap[K, V]) CompareAndSwap(k K, old, nv V) (swapped bool) {
+	s := m.shard(k)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cur, exist := s.m[k]
+	if !exist || any(cur) != any(old) {
+		return false
+	}
+	s.m[k] = nv
+	return true
+}
+
+// CompareAndDelete deletes k if its value equals to old, panics if V is not comparable at runtime
+func (m *ConcurrentMap[K, V]) CompareAndDelete(k K, old V) (deleted bool) {
+	s := m.shard(k)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cur, exist := s.m[k]
+	if !exist || any(cur) != any(old) {
+		return false
+	}
+	delete(s.m, k)
+	return true
+}
+
+// Update atomically changes the value of k with updater, which receives the current value and
+// whether it exists, and returns the new value and whether to keep it (false deletes k). updater is
+// called under the lock of the shard, so it must not access the map itself.
+func (m *ConcurrentMap[K, V]) Update(k K, updater func(old V, exist bool) (nv V, keep bool)) (V, bool) {
+	s := m.shard(k)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	old, exist := s.m[k]
+	nv, keep := updater(old, exist)
+	if keep {
+		s.m[k] = nv
+	} else {
+		delete(s.m, k)
+	}
+	return nv, keep
+}
+
+// All iterates a snapshot of each shard in turn
+func (m *ConcurrentMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, s := range m.shards {
+			s.lock.RLock()
+			snapshot := make(map[K]V, len(s.m))
+			for k, v := range s.m {
+				snapshot[k] = v
+			}
+			s.lock.RUnlock()
+			for k, v := range snapshot {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Snapshot copies all entries to a KMap
+func (m *ConcurrentMap[K, V]) Snapshot() KMap[K, V] {
+	return KMap[K, V](nil).Puts(m.All())
+}
+
+func (m *ConcurrentMap[K, V]) KeySeq(filters ...func(k K, v V) bool) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		filter := VariadicParam(filters)
+		for k, v := range m.All() {
+			if filter == nil || filter(k, v) {
+				if !yield(k) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (m *ConcurrentMap[K, V]) ValuesSeq(filters ...func(k K, v V) bool) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		filter := VariadicParam(filters)
+		for k, v := range m.All() {
+			if filter == nil || filter(k, v) {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (m *ConcurrentMap[K, V]) Keys(filters ...func(k K, v V) bool) []K {
+	return slices.Collect(m.KeySeq(filters...))
+}
+
+func (m *ConcurrentMap[K, V]) Values(filters ...func(k K, v V) bool) []V {
+	return slices.Collect(m.ValuesSeq(filters...))
+}
+
+func (m *ConcurrentMap[K, V]) List(keys ...K) []V {
+	ret := make([]V, len(keys))
+	for i, k := range keys {
+		ret[i], _ = m.Get(k)
+	}
+	return ret
+}
+
+// SubMap generate a new map with all keys from ks, even if there's no corresponding value in m.
+func (m *ConcurrentMap[K, V]) SubMap(ks ...K) KMap[K, V] {
+	if len(ks) == 0 {
+		return m.Snapshot()
+	}
+	ret := make(KMap[K, V], len(ks))
+	for _, k := range ks {
+		ret[k], _ = m.Get(k)
+	}
+	return ret
+}
+
+func (m *ConcurrentMap[K, V]) RangeSubMap(batchSize int, ranger func(m KMap[K, V]) bool) {
+	m.Snapshot().RangeSubMap(batchSize, ranger)
+}
+
+// ConcurrentSet 分片加锁的并发安全set
+type ConcurrentSet[K comparable] struct {
+	m *ConcurrentMap[K, struct{}]
+}
+
+func NewConcurrentSet[K comparable](shardCount ...int) *ConcurrentSet[K] {
+	return &ConcurrentSet[K]{m: NewConcurrentMap[K, struct{}](shardCount...)}
+}
+
+func (s *ConcurrentSet[K]) Add(ks ...K) *ConcurrentSet[K] {
+	for _, k := range ks {
+		s.m.Put(k, struct{}{})
+	}
+	return s
+}
+
+func (s *ConcurrentSet[K]) Adds(it iter.Seq[K]) *ConcurrentSet[K] {
+	for k := range it {
+		s.m.Put(k, struct{}{})
+	}
+	return s
+}
+
+// CAS adds k and returns true if k is not in the set
+func (s *ConcurrentSet[K]) CAS(k K) (changed bool) {
+	_, loaded := s.m.GetOrPut(k, struct{}{})
+	return !loaded
+}
+
+func (s *ConcurrentSet[K]) Delete(ks ...K) *ConcurrentSet[K] {
+	s.m.Delete(ks...)
+	return s
+}
+
+func (s *ConcurrentSet[K]) IsExist(k K) bool { return s.m.IsExist(k) }
+func (s *ConcurrentSet[K]) Len() int         { return s.m.Len() }
+func (s *ConcurrentSet[K]) Clear()           { s.m.Clear() }
+
+// Keys iterates a snapshot of each shard in turn
+func (s *ConcurrentSet[K]) Keys() iter.Seq[K] { return s.m.KeySeq() }
+
+func (s *ConcurrentSet[K]) Slice() []K { return s.m.Keys() }
+
+func (s *ConcurrentSet[K]) Snapshot() KSet[K] {
+	return make(KSet[K]).Adds(s.m.KeySeq())
+}
+
+func (s *ConcurrentSet[K]) RangeSubSet(batchSize int, ranger func(s KSet[K]) bool) {
+	s.Snapshot().RangeSubSet(batchSize, ranger)
+}
+
+// SyncOrderMap 加读写锁的OrderMap，遍历基于快照
+type SyncOrderMap[K comparable, V any] struct {
+	lock sync.RWMutex
+	om   *OrderMap[K, V]
+}
+
+func NewSyncOrderMap[K comparable, V any]() *SyncOrderMap[K, V] {
+	return &SyncOrderMap[K, V]{om: NewOrderMap[K, V]()}
+}
+
+func (m *SyncOrderMap[K, V]) Put(k K, v V) *SyncOrderMap[K, V] {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.om.Put(k, v)
+	return m
+}
+
+// Set updates the value of k, or appends k to the end
+func (m *SyncOrderMap[K, V]) Set(k K, v V) *SyncOrderMap[K, V] {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.om.Set(k, v)
+	return m
+}
+
+func (m *SyncOrderMap[K, V]) Get(k K) (V, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.om.Get(k)
+}
+
+func (m *SyncOrderMap[K, V]) IsExist(k K) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.om.IsExist(k)
+}
+
+func (m *SyncOrderMap[K, V]) Del(k K) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.om.Del(k)
+}
+
+func (m *SyncOrderMap[K, V]) Delete(ks ...K) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, k := range ks {
+		m.om.Del(k)
+	}
+}
+
+// LoadAndDelete deletes k and returns the value before deleting
+func (m *SyncOrderMap[K, V]) LoadAndDelete(k K) (v V, loaded bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if v, loaded = m.om.Get(k); loaded {
+		m.om.Del(k)
+	}
+	return v, loaded
+}
+
+func (m *SyncOrderMap[K, V]) Len() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.om.Len()
+}
+
+func (m *SyncOrderMap[K, V]) Clear() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.om = NewOrderMap[K, V]()
+}
+
+// GetOrPut returns the existing value of k if present, otherwise appends v and returns it
+func (m *SyncOrderMap[K, V]) GetOrPut(k K, v V) (actual V, loaded bool) {
+	return m.GetOrCompute(k, func() V { return v })
+}
+
+// GetOrCompute returns the existing value of k, or appends the computed value to the end
+func (m *SyncOrderMap[K, V]) GetOrCompute(k K, compute func() V) (actual V, loaded bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if actual, loaded = m.om.Get(k); loaded {
+		return
+	}
+	actual = compute()
+	m.om.Put(k, actual)
+	return actual, false
+}
+
+// CompareAndSwap swaps the value of k to nv if the current value equals to old, the position of k
+// is not changed. Like sync.Map, it panics if V is not comparable at runtime.
+func (m *SyncOrderMap[K, V]) CompareAndSwap(k K, old, nv V) (swapped bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	cur, exist := m.om.Get(k)
+	if !exist || any(cur) != any(old) {
+		return false
+	}
+	m.om.Set(k, nv)
+	return true
+}
+
+// CompareAndDelete deletes k if its value equals to old, panics if V is not comparable at runtime
+func (m *SyncOrderMap[K, V]) CompareAndDelete(k K, old V) (deleted bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	cur, exist := m.om.Get(k)
+	if !exist || any(cur) != any(old) {
+		return false
+	}
+	m.om.Del(k)
+	return true
+}
+
+// Update atomically changes the value of k with updater, new keys are appended to the end and
+// keep=false deletes k
+func (m *SyncOrderMap[K, V]) Update(k K, updater func(old V, exist bool) (nv V, keep bool)) (V, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	old, exist := m.om.Get(k)
+	nv, keep := updater(old, exist)
+	if keep {
+		m.om.Set(k, nv)
+	} else {
+		m.om.Del(k)
+	}
+	return nv, keep
+}
+
+func (m *SyncOrderMap[K, V]) snapshot() ([]K, []V) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ks := make([]K, 0, m.om.Len())
+	vs := make([]V, 0, m.om.Len())
+	for k, v := range m.om.All() {
+		ks = append(ks, k)
+		vs = append(vs, v)
+	}
+	return ks, vs
+}
+
+// All iterates a snapshot in order
+func (m *SyncOrderMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		ks, vs := m.snapshot()
+		for i, k := range ks {
+			if !yield(k, vs[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Keys iterates a snapshot of keys in order
+func (m *SyncOrderMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		ks, _ := m.snapshot()
+		for _, k := range ks {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values iterates a snapshot of values in order
+func (m *SyncOrderMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		_, vs := m.snapshot()
+		for _, v := range vs {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot copies all entries to an OrderMap
+func (m *SyncOrderMap[K, V]) Snapshot() *OrderMap[K, V] {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.om.Clone()
+}
+
+// RangeSubMap calls ranger with sub maps of at most batchSize entries of a snapshot in order
+func (m *SyncOrderMap[K, V]) RangeSubMap(batchSize int, ranger func(m *OrderMap[K, V]) bool) {
+	ks, vs := m.snapshot()
+	if batchSize <= 0 {
+		batchSize = len(ks)
+	}
+	for start := 0; start < len(ks); start += batchSize {
+		sub := NewOrderMap[K, V]()
+		for i := start; i < min(start+batchSize, len(ks)); i++ {
+			sub.Put(ks[i], vs[i])
+		}
+		if !ranger(sub) {
+			return
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentMap(t *testing.T) {
+	m := NewConcurrentMap[int, int](5)
+	if len(m.shards) != 8 {
+		t.Fatalf("shards: %d", len(m.shards))
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Put(g*1000+i, i)
+				m.Update(-1, func(old int, _ bool) (int, bool) { return old + 1, true })
+				m.GetOrCompute(i%10, func() int { return g })
+				for range m.All() {
+					break
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if m.Len() != 8000+1 {
+		t.Fatalf("len: %d", m.Len())
+	}
+	if v, _ := m.Get(-1); v != 8000 {
+		t.Fatalf("counter: %d", v)
+	}
+
+	m.Clear()
+	m.Put(1, 10).Put(2, 20).Merge(KMap[int, int]{3: 30})
+	if v, loaded := m.GetOrPut(1, 11); !loaded || v != 10 {
+		t.Fatalf("get or put: %d %t", v, loaded)
+	}
+	if m.CompareAndSwap(1, 11, 12) || !m.CompareAndSwap(1, 10, 12) {
+		t.Fatal("compare and swap failed")
+	}
+	if m.CompareAndDelete(2, 21) || !m.CompareAndDelete(2, 20) || m.IsExist(2) {
+		t.Fatal("compare and delete failed")
+	}
+	if _, keep := m.Update(3, func(old int, exist bool) (int, bool) { return 0, false }); keep || m.IsExist(3) {
+		t.Fatal("update delete failed")
+	}
+	if v, ok := m.LoadAndDelete(1); !ok || v != 12 || m.Len() != 0 {
+		t.Fatalf("load and delete: %d %t", v, ok)
+	}
+
+	m.Puts(maps.All(map[int]int{1: 1, 2: 2, 3: 3, 4: 4}))
+	keys := m.Keys(func(k, v int) bool { return v%2 == 0 })
+	slices.Sort(keys)
+	if !slices.Equal(keys, []int{2, 4}) {
+		t.Fatalf("keys: %v", keys)
+	}
+	if vs := m.List(1, 5); !slices.Equal(vs, []int{1, 0}) {
+		t.Fatalf("list: %v", vs)
+	}
+	if sub := m.SubMap(1, 5); len(sub) != 2 || sub[1] != 1 {
+		t.Fatalf("sub map: %v", sub)
+	}
+	count := 0
+	m.RangeSubMap(3, func(sub KMap[int, int]) bool {
+		count += len(sub)
+		return true
+	})
+	if count != 4 {
+		t.Fatalf("range sub map: %d", count)
+	}
+	// modifying during iteration is allowed
+	for k := range m.All() {
+		m.Delete(k)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("delete in iteration: %d", m.Len())
+	}
+}
+
+func TestConcurrentSet(t *testing.T) {
+	s := NewConcurrentSet[string]()
+	var wg sync.WaitGroup
+	added := NewConcurrentMap[string, int]()
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, k := range []string{"a", "b", "c"} {
+				if s.CAS(k) {
+					added.Update(k, func(old int, _ bool) (int, bool) { return old + 1, true })
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 3 || added.Len() != 3 || slices.Max(added.Values()) != 1 {
+		t.Fatalf("cas: %v %v", s.Slice(), added.Snapshot())
+	}
+	s.Delete("a").Add("d")
+	if !s.Snapshot().Equal(NewKSet("b", "c", "d")) || s.IsExist("a") {
+		t.Fatalf("snapshot: %v", s.Slice())
+	}
+}
+
+func TestSyncOrderMap(t *testing.T) {
+	m := NewSyncOrderMap[string, int]()
+	m.Put("a", 1).Put("b", 2)
+	if v, loaded := m.GetOrCompute("c", func() int { return 3 }); loaded || v != 3 {
+		t.Fatalf("compute: %d %t", v, loaded)
+	}
+	m.Update("a", func(old int, exist bool) (int, bool) { return old + 10, true })
+	m.Update("b", func(int, bool) (int, bool) { return 0, false })
+	m.Update("d", func(old int, exist bool) (int, bool) { return 4, true })
+	var got []int
+	for k, v := range m.All() {
+		m.Del(k)
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{11, 3, 4}) || m.Len() != 0 {
+		t.Fatalf("all: %v %d", got, m.Len())
+	}
+
+	m.Set("x", 1).Set("y", 2).Set("z", 3).Set("x", 10)
+	if !m.CompareAndSwap("y", 2, 20) || m.CompareAndSwap("y", 2, 30) || m.CompareAndSwap("w", 0, 1) {
+		t.Fatal("compare and swap")
+	}
+	if m.CompareAndDelete("z", 0) || !m.CompareAndDelete("z", 3) || m.IsExist("z") {
+		t.Fatal("compare and delete")
+	}
+	if v, loaded := m.GetOrPut("x", 0); !loaded || v != 10 {
+		t.Fatalf("get or put: %d %t", v, loaded)
+	}
+	if ks, vs := slices.Collect(m.Keys()), slices.Collect(m.Values()); !slices.Equal(ks, []string{"x", "y"}) || !slices.Equal(vs, []int{10, 20}) {
+		t.Fatalf("keys %v values %v", ks, vs)
+	}
+	m.Put("u", 5).Put("v", 6)
+	var batches [][]string
+	m.RangeSubMap(3, func(sub *OrderMap[string, int]) bool {
+		batches = append(batches, slices.Collect(sub.Keys()))
+		return true
+	})
+	if len(batches) != 2 || !slices.Equal(batches[0], []string{"x", "y", "u"}) || !slices.Equal(batches[1], []string{"v"}) {
+		t.Fatalf("range sub map: %v", batches)
+	}
+	if snap := m.Snapshot(); snap.Len() != 4 {
+		t.Fatalf("snapshot: %d", snap.Len())
+	}
+	if v, loaded := m.LoadAndDelete("u"); !loaded || v != 5 {
+		t.Fatalf("load and delete: %d %t", v, loaded)
+	}
+	m.Delete("x", "y")
+	if m.Len() != 1 {
+		t.Fatalf("delete: %d", m.Len())
+	}
+	m.Clear()
+	if m.Len() != 0 || m.IsExist("v") {
+		t.Fatal("clear")
+	}
+}
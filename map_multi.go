@@ -0,0 +1,332 @@
+package tools
+
+import (
+	"errors"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// MultiMap 一个key对应多个value，按加入顺序保存，允许重复
+type MultiMap[K comparable, V any] map[K][]V
+
+// GroupBy groups ts by key, the order of ts is kept in each group
+func GroupBy[T any, K comparable](ts []T, key func(T) K) MultiMap[K, T] {
+	m := make(MultiMap[K, T])
+	for _, t := range ts {
+		m.Put(key(t), t)
+	}
+	return m
+}
+
+func (mm MultiMap[K, V]) Put(k K, vs ...V) MultiMap[K, V] {
+	if len(vs) == 0 {
+		return mm
+	}
+	m := mm
+	if m == nil {
+		m = make(MultiMap[K, V])
+	}
+	m[k] = append(m[k], vs...)
+	return m
+}
+
+func (mm MultiMap[K, V]) Get(k K) []V {
+	return mm[k]
+}
+
+func (mm MultiMap[K, V]) IsExist(k K) bool {
+	_, exist := mm[k]
+	return exist
+}
+
+// Len returns the number of all values
+func (mm MultiMap[K, V]) Len() int {
+	n := 0
+	for _, vs := range mm {
+		n += len(vs)
+	}
+	return n
+}
+
+func (mm MultiMap[K, V]) Delete(ks ...K) {
+	for _, k := range ks {
+		delete(mm, k)
+	}
+}
+
+// Remove removes values of k matched, and the key if no value left. Returns the number of removed
+// values.
+func (mm MultiMap[K, V]) Remove(k K, match func(v V) bool) int {
+	vs, exist := mm[k]
+	if !exist {
+		return 0
+	}
+	left := slices.DeleteFunc(vs, match)
+	if len(left) == 0 {
+		delete(mm, k)
+	} else {
+		mm[k] = left
+	}
+	return len(vs) - len(left)
+}
+
+func (mm MultiMap[K, V]) KeySeq() iter.Seq[K] {
+	return maps.Keys(mm)
+}
+
+func (mm MultiMap[K, V]) Keys() []K {
+	return slices.Collect(mm.KeySeq())
+}
+
+// All iterates every key-value pair
+func (mm MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, vs := range mm {
+			for _, v := range vs {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Groups iterates every key with its values
+func (mm MultiMap[K, V]) Groups() iter.Seq2[K, []V] {
+	return maps.All(mm)
+}
+
+func (mm MultiMap[K, V]) ToKMap() KMap[K, []V] {
+	return KMap[K, []V](mm)
+}
+
+// First returns a KMap of the first value of each key
+func (mm MultiMap[K, V]) First() KMap[K, V] {
+	km := make(KMap[K, V], len(mm))
+	for k, vs := range mm {
+		if len(vs) > 0 {
+			km[k] = vs[0]
+		}
+	}
+	return km
+}
+
+// SetMultiMap 一个key对应多个value，value不重复
+type SetMultiMap[K comparable, V comparable] map[K]KSet[V]
+
+// GroupSetBy groups ts by key, duplicated values are merged
+func GroupSetBy[T comparable, K comparable](ts []T, key func(T) K) SetMultiMap[K, T] {
+	m := make(SetMultiMap[K, T])
+	for _, t := range ts {
+		m.Put(key(t), t)
+	}
+	return m
+}
+
+// InvertKMap groups keys of km by their values
+func InvertKMap[K comparable, V comparable](km KMap[K, V]) SetMultiMap[V, K] {
+	m := make(SetMultiMap[V, K])
+	for k, v := range km {
+		m.Put(v, k)
+	}
+	return m
+}
+
+// SetMultiMapFromKKMap collects the second level keys of kkm
+func SetMultiMapFromKKMap[K1 comparable, K2 comparable, V any](kkm KKMap[K1, K2, V]) SetMultiMap[K1, K2] {
+	m := make(SetMultiMap[K1, K2], len(kkm))
+	for k1, km := range kkm {
+		if len(km) > 0 {
+			m.Put(k1, slices.Collect(maps.Keys(km))...)
+		}
+	}
+	return m
+}
+
+func (sm SetMultiMap[K, V]) Put(k K, vs ...V) SetMultiMap[K, V] {
+	if len(vs) == 0 {
+		return sm
+	}
+	m := sm
+	if m == nil {
+		m = make(SetMultiMap[K, V])
+	}
+	m[k] = m[k].Append(vs...)
+	return m
+}
+
+func (sm SetMultiMap[K, V]) Get(k K) KSet[V] {
+	return sm[k]
+}
+
+func (sm SetMultiMap[K, V]) IsExist(k K) bool {
+	_, exist := sm[k]
+	return exist
+}
+
+func (sm SetMultiMap[K, V]) Contains(k K, v V) bool {
+	return sm[k].IsExist(v)
+}
+
+// Len returns the number of all values
+func (sm SetMultiMap[K, V]) Len() int {
+	n := 0
+	for _, vs := range sm {
+		n += len(vs)
+	}
+	return n
+}
+
+func (sm SetMultiMap[K, V]) Delete(ks ...K) {
+	for _, k := range ks {
+		delete(sm, k)
+	}
+}
+
+// Remove removes vs from k, and the key if no value left
+func (sm SetMultiMap[K, V]) Remove(k K, vs ...V) {
+	s, exist := sm[k]
+	if !exist {
+		return
+	}
+	s.Delete(vs...)
+	if len(s) == 0 {
+		delete(sm, k)
+	}
+}
+
+func (sm SetMultiMap[K, V]) KeySeq() iter.Seq[K] {
+	return maps.Keys(sm)
+}
+
+func (sm SetMultiMap[K, V]) Keys() []K {
+	return slices.Collect(sm.KeySeq())
+}
+
+// All iterates every key-value pair
+func (sm SetMultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, vs := range sm {
+			for v := range vs {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Groups iterates every key with its values
+func (sm SetMultiMap[K, V]) Groups() iter.Seq2[K, KSet[V]] {
+	return maps.All(sm)
+}
+
+// Invert returns a new SetMultiMap from values to keys
+func (sm SetMultiMap[K, V]) Invert() SetMultiMap[V, K] {
+	m := make(SetMultiMap[V, K])
+	for k, v := range sm.All() {
+		m.Put(v, k)
+	}
+	return m
+}
+
+func (sm SetMultiMap[K, V]) ToKKMap() KKMap[K, V, struct{}] {
+	kkm := make(KKMap[K, V, struct{}], len(sm))
+	for k, v := range sm.All() {
+		kkm.Put(k, v, struct{}{})
+	}
+	return kkm
+}
+
+var ErrBiMapValueExists = errors.New("tools: value already bound to another key")
+
+// BiMap 一对一的双向map，可以通过value查找key。线程不安全。
+type BiMap[K comparable, V comparable] struct {
+	kv KMap[K, V]
+	vk KMap[V, K]
+}
+
+func NewBiMap[K comparable, V comparable]() *BiMap[K, V] {
+	return &BiMap[K, V]{kv: make(KMap[K, V]), vk: make(KMap[V, K])}
+}
+
+// NewBiMapFrom returns ErrBiMapValueExists if a value in km is bound to more than one key
+func NewBiMapFrom[K comparable, V comparable](km KMap[K, V]) (*BiMap[K, V], error) {
+	bm := NewBiMap[K, V]()
+	for k, v := range km {
+		if err := bm.Put(k, v); err != nil {
+			return nil, err
+		}
+	}
+	return bm, nil
+}
+
+// Put binds k with v, the old value of k is unbound. Returns ErrBiMapValueExists if v is bound to
+// another key.
+func (bm *BiMap[K, V]) Put(k K, v V) error {
+	if ok, exist := bm.vk[v]; exist && ok != k {
+		return ErrBiMapValueExists
+	}
+	bm.put(k, v)
+	return nil
+}
+
+// ForcePut binds k with v, and unbinds the old value of k and the old key of v
+func (bm *BiMap[K, V]) ForcePut(k K, v V) {
+	if ok, exist := bm.vk[v]; exist {
+		delete(bm.kv, ok)
+	}
+	bm.put(k, v)
+}
+
+func (bm *BiMap[K, V]) put(k K, v V) {
+	if ov, exist := bm.kv[k]; exist {
+		delete(bm.vk, ov)
+	}
+	bm.kv[k] = v
+	bm.vk[v] = k
+}
+
+func (bm *BiMap[K, V]) Get(k K) (V, bool) {
+	return bm.kv.Get(k)
+}
+
+func (bm *BiMap[K, V]) GetKey(v V) (K, bool) {
+	return bm.vk.Get(v)
+}
+
+func (bm *BiMap[K, V]) IsExist(k K) bool      { return bm.kv.IsExist(k) }
+func (bm *BiMap[K, V]) IsExistValue(v V) bool { return bm.vk.IsExist(v) }
+func (bm *BiMap[K, V]) Len() int              { return len(bm.kv) }
+
+func (bm *BiMap[K, V]) Delete(k K) bool {
+	v, exist := bm.kv[k]
+	if exist {
+		delete(bm.kv, k)
+		delete(bm.vk, v)
+	}
+	return exist
+}
+
+func (bm *BiMap[K, V]) DeleteValue(v V) bool {
+	k, exist := bm.vk[v]
+	if exist {
+		delete(bm.kv, k)
+		delete(bm.vk, v)
+	}
+	return exist
+}
+
+func (bm *BiMap[K, V]) All() iter.Seq2[K, V] { return maps.All(bm.kv) }
+func (bm *BiMap[K, V]) Keys() []K            { return bm.kv.Keys() }
+func (bm *BiMap[K, V]) Values() []V          { return bm.kv.Values() }
+
+// Inverse returns the BiMap from values to keys, which shares the data with bm
+func (bm *BiMap[K, V]) Inverse() *BiMap[V, K] {
+	return &BiMap[V, K]{kv: bm.vk, vk: bm.kv}
+}
+
+func (bm *BiMap[K, V]) ToKMap() KMap[K, V] {
+	return maps.Clone(bm.kv)
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMultiMap(t *testing.T) {
+	words := []string{"apple", "avocado", "banana", "apple", "blueberry", "cherry"}
+	mm := GroupBy(words, func(s string) byte { return s[0] })
+	if !slices.Equal(mm.Get('a'), []string{"apple", "avocado", "apple"}) || mm.Len() != 6 || len(mm.Keys()) != 3 {
+		t.Fatalf("group by: %v", mm)
+	}
+	if n := mm.Remove('a', func(s string) bool { return s == "apple" }); n != 2 || !slices.Equal(mm.Get('a'), []string{"avocado"}) {
+		t.Fatalf("remove: %d %v", n, mm.Get('a'))
+	}
+	mm.Remove('c', func(string) bool { return true })
+	if mm.IsExist('c') {
+		t.Fatal("empty key should be removed")
+	}
+	var nilMM MultiMap[string, int]
+	nilMM = nilMM.Put("x", 1, 2).Put("x", 3)
+	if !slices.Equal(nilMM.Get("x"), []int{1, 2, 3}) || nilMM.First()["x"] != 1 {
+		t.Fatalf("put: %v", nilMM)
+	}
+	if nilMM.Put("y").IsExist("y") {
+		t.Fatal("put without values should not add the key")
+	}
+	count := 0
+	for range mm.All() {
+		count++
+	}
+	if count != mm.Len() {
+		t.Fatalf("all: %d", count)
+	}
+
+	sm := GroupSetBy(words, func(s string) int { return len(s) })
+	if !sm.Get(5).Equal(NewKSet("apple")) || sm.Len() != 5 || !sm.Contains(6, "banana") || !sm.Contains(6, "cherry") {
+		t.Fatalf("group set by: %v", sm)
+	}
+	sm.Remove(5, "apple")
+	if sm.IsExist(5) {
+		t.Fatal("empty key should be removed")
+	}
+	if sm.Put(8).IsExist(8) || (SetMultiMap[int, string])(nil).Put(8) != nil {
+		t.Fatal("put without values should not add the key")
+	}
+	inv := sm.Invert()
+	if !inv.Get("banana").Equal(NewKSet(6)) || inv.Len() != sm.Len() {
+		t.Fatalf("invert: %v", inv)
+	}
+	kkm := sm.ToKKMap()
+	if !kkm.IsExist(9, "blueberry") || !SetMultiMapFromKKMap(kkm).Get(6).Equal(sm.Get(6)) {
+		t.Fatalf("kkmap: %v", kkm)
+	}
+	byLen := InvertKMap(KMap[string, int]{"a": 1, "b": 2, "c": 1})
+	if !byLen.Get(1).Equal(NewKSet("a", "c")) {
+		t.Fatalf("invert kmap: %v", byLen)
+	}
+}
+
+func TestBiMap(t *testing.T) {
+	bm := NewBiMap[string, int]()
+	if err := bm.Put("a", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.Put("b", 1); !errors.Is(err, ErrBiMapValueExists) {
+		t.Fatalf("duplicated value: %v", err)
+	}
+	if err := bm.Put("a", 2); err != nil || bm.IsExistValue(1) || bm.Len() != 1 {
+		t.Fatal("rebinding key should unbind the old value")
+	}
+	bm.Put("b", 3)
+	bm.ForcePut("c", 3)
+	if bm.IsExist("b") || bm.Len() != 2 {
+		t.Fatal("force put should unbind the old key")
+	}
+	if k, ok := bm.GetKey(2); !ok || k != "a" {
+		t.Fatalf("get key: %s", k)
+	}
+	inv := bm.Inverse()
+	if v, ok := inv.Get(3); !ok || v != "c" {
+		t.Fatalf("inverse: %s", v)
+	}
+	inv.Delete(3)
+	if bm.IsExist("c") || !bm.DeleteValue(2) || bm.Len() != 0 {
+		t.Fatal("inverse should share data")
+	}
+
+	km := KMap[string, string]{"x": "X", "y": "Y"}
+	from, err := NewBiMapFrom(km)
+	if err != nil || !maps.Equal(from.ToKMap(), km) {
+		t.Fatalf("from kmap: %v", err)
+	}
+	km["z"] = "X"
+	if _, err = NewBiMapFrom(km); err == nil {
+		t.Fatal("duplicated values should fail")
+	}
+	keys := from.Keys()
+	slices.Sort(keys)
+	if strings.Join(keys, "") != "xy" {
+		t.Fatalf("keys: %v", keys)
+	}
+}
@@ -0,0 +1,384 @@
+package tools
+
+import (
+	"bytes"
+	"cmp"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// smallFirst returns the smaller one of two sets first
+func smallFirst[K comparable](a, b KSet[K]) (KSet[K], KSet[K]) {
+	if len(a) <= len(b) {
+		return a, b
+	}
+	return b, a
+}
+
+// Union returns a new set with keys in km or o
+func (km KSet[K]) Union(o KSet[K]) KSet[K] {
+	ret := make(KSet[K], max(len(km), len(o)))
+	for k := range km {
+		ret[k] = struct{}{}
+	}
+	for k := range o {
+		ret[k] = struct{}{}
+	}
+	return ret
+}
+
+// Intersection returns a new set with keys in both km and o
+func (km KSet[K]) Intersection(o KSet[K]) KSet[K] {
+	small, large := smallFirst(km, o)
+	ret := make(KSet[K])
+	for k := range small {
+		if large.IsExist(k) {
+			ret[k] = struct{}{}
+		}
+	}
+	return ret
+}
+
+// Difference returns a new set with keys in km but not in o
+func (km KSet[K]) Difference(o KSet[K]) KSet[K] {
+	ret := make(KSet[K])
+	for k := range km {
+		if !o.IsExist(k) {
+			ret[k] = struct{}{}
+		}
+	}
+	return ret
+}
+
+// SymmetricDifference returns a new set with keys in exactly one of km and o
+func (km KSet[K]) SymmetricDifference(o KSet[K]) KSet[K] {
+	ret := km.Difference(o)
+	for k := range o {
+		if !km.IsExist(k) {
+			ret[k] = struct{}{}
+		}
+	}
+	return ret
+}
+
+// UnionWith adds all keys of o into km, returns a new set if km is nil
+func (km KSet[K]) UnionWith(o KSet[K]) KSet[K] {
+	if len(o) == 0 {
+		return km
+	}
+	m := km
+	if m == nil {
+		m = make(KSet[K], len(o))
+	}
+	for k := range o {
+		m[k] = struct{}{}
+	}
+	return m
+}
+
+// IntersectWith removes keys not in o from km
+func (km KSet[K]) IntersectWith(o KSet[K]) KSet[K] {
+	for k := range km {
+		if !o.IsExist(k) {
+			delete(km, k)
+		}
+	}
+	return km
+}
+
+// DifferenceWith removes keys in o from km
+func (km KSet[K]) DifferenceWith(o KSet[K]) KSet[K] {
+	if len(km) == 0 {
+		return km
+	}
+	for k := range o {
+		delete(km, k)
+	}
+	return km
+}
+
+// SymmetricDifferenceWith removes keys in both km and o from km, and adds keys only in o
+func (km KSet[K]) SymmetricDifferenceWith(o KSet[K]) KSet[K] {
+	if len(o) == 0 {
+		return km
+	}
+	m := km
+	if m == nil {
+		m = make(KSet[K], len(o))
+	}
+	for k := range o {
+		if _, exist := m[k]; exist {
+			delete(m, k)
+		} else {
+			m[k] = struct{}{}
+		}
+	}
+	return m
+}
+
+// IsSubset reports whether all keys of km are in o
+func (km KSet[K]) IsSubset(o KSet[K]) bool {
+	if len(km) > len(o) {
+		return false
+	}
+	for k := range km {
+		if !o.IsExist(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether all keys of o are in km
+func (km KSet[K]) IsSuperset(o KSet[K]) bool {
+	return o.IsSubset(km)
+}
+
+// Disjoint reports whether km and o have no key in common
+func (km KSet[K]) Disjoint(o KSet[K]) bool {
+	small, large := smallFirst(km, o)
+	for k := range small {
+		if large.IsExist(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// NotIn return a sequence that in input but not in current set
+func (km KSet[K]) NotIn(input iter.Seq[K]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range input {
+			if !km.IsExist(k) {
+				if !yield(k) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// UnionSeq return a sequence of keys in current set followed by keys only in input, keys in input
+// are deduplicated.
+func (km KSet[K]) UnionSeq(input iter.Seq[K]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range km {
+			if !yield(k) {
+				return
+			}
+		}
+		seen := make(KSet[K])
+		for k := range input {
+			if km.IsExist(k) || seen.IsExist(k) {
+				continue
+			}
+			seen[k] = struct{}{}
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Deletes removes all keys in input from km
+func (km KSet[K]) Deletes(input iter.Seq[K]) KSet[K] {
+	for k := range input {
+		delete(km, k)
+	}
+	return km
+}
+
+// Retains keeps only keys in input, the memory used is limited by the size of km
+func (km KSet[K]) Retains(input iter.Seq[K]) KSet[K] {
+	if len(km) == 0 {
+		return km
+	}
+	retained := make(KSet[K])
+	for k := range km.In(input) {
+		retained[k] = struct{}{}
+	}
+	for k := range km {
+		if !retained.IsExist(k) {
+			delete(km, k)
+		}
+	}
+	return km
+}
+
+// ContainsAll reports whether all keys in input are in km
+func (km KSet[K]) ContainsAll(input iter.Seq[K]) bool {
+	for range km.NotIn(input) {
+		return false
+	}
+	return true
+}
+
+// ContainsAny reports whether any key in input is in km
+func (km KSet[K]) ContainsAny(input iter.Seq[K]) bool {
+	for range km.In(input) {
+		return true
+	}
+	return false
+}
+
+// compareComparable orders numbers, strings and bools by their values, and others by their
+// formatted strings.
+func compareComparable[K comparable](a, b K) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(va.Int(), vb.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(va.Uint(), vb.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(va.Float(), vb.Float())
+	case reflect.String:
+		return strings.Compare(va.String(), vb.String())
+	case reflect.Bool:
+		return cmp.Compare(boolToInt(va.Bool()), boolToInt(vb.Bool()))
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Sorted returns the keys in ascending order, see compareComparable
+func (km KSet[K]) Sorted() []K {
+	ks := km.Slice()
+	slices.SortFunc(ks, compareComparable[K])
+	return ks
+}
+
+// MarshalJSON writes the set as a sorted array, nil set as null
+func (km KSet[K]) MarshalJSON() ([]byte, error) {
+	if km == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(km.Sorted())
+}
+
+// UnmarshalJSON accepts a JSON array, or an object like {"a":{}} which is the form of KSet marshalled
+// as a plain map in former versions.
+func (km *KSet[K]) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var m map[K]struct{}
+		if err := json.Unmarshal(trimmed, &m); err != nil {
+			return err
+		}
+		*km = m
+		return nil
+	}
+	var ks []K
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return err
+	}
+	if ks == nil {
+		*km = nil
+		return nil
+	}
+	*km = NewKSet(ks...)
+	return nil
+}
+
+// scanKSet parses a JSON array or comma separated values
+func scanKSet[K comparable](value any, typeName string) (KSet[K], error) {
+	bs, err := jsonScanBytes(value, typeName)
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	bs = bytes.TrimSpace(bs)
+	if len(bs) > 0 && (bs[0] == '[' || bs[0] == '{' || bytes.Equal(bs, []byte("null"))) {
+		var s KSet[K]
+		if err = s.UnmarshalJSON(bs); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	s := make(KSet[K])
+	if len(bs) == 0 {
+		return s, nil
+	}
+	for _, item := range strings.Split(string(bs), ",") {
+		k, err := unmarshalMapKey[K](strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		s[k] = struct{}{}
+	}
+	return s, nil
+}
+
+// Scan accepts a JSON array or comma separated values
+func (km *KSet[K]) Scan(value any) error {
+	s, err := scanKSet[K](value, "KSet")
+	if err != nil {
+		return err
+	}
+	*km = s
+	return nil
+}
+
+// Value stores the set as a sorted JSON array
+func (km KSet[K]) Value() (driver.Value, error) {
+	if km == nil {
+		return nil, nil
+	}
+	return km.MarshalJSON()
+}
+
+// CSV returns the sorted keys separated by commas, the keys are formatted like JSON object keys and
+// must not contain commas.
+func (km KSet[K]) CSV() (string, error) {
+	items := make([]string, 0, len(km))
+	for _, k := range km.Sorted() {
+		item, err := marshalMapKey(k)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(item, ",") {
+			return "", fmt.Errorf("tools: csv item %q contains comma", item)
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// CSVSet a KSet stored as comma separated values in database, JSON arrays are also accepted when
+// scanning.
+type CSVSet[K comparable] KSet[K]
+
+func (s CSVSet[K]) ToKSet() KSet[K] { return KSet[K](s) }
+
+func (s *CSVSet[K]) Scan(value any) error {
+	ks, err := scanKSet[K](value, "CSVSet")
+	if err != nil {
+		return err
+	}
+	*s = CSVSet[K](ks)
+	return nil
+}
+
+func (s CSVSet[K]) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return KSet[K](s).CSV()
+}
+
+func (s CSVSet[K]) MarshalJSON() ([]byte, error) {
+	return KSet[K](s).MarshalJSON()
+}
+
+func (s *CSVSet[K]) UnmarshalJSON(data []byte) error {
+	return (*KSet[K])(s).UnmarshalJSON(data)
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestKSet_Algebra(t *testing.T) {
+	a, b := NewKSet(1, 2, 3, 4), NewKSet(3, 4, 5)
+	tests := []struct {
+		name string
+		got  KSet[int]
+		want []int
+	}{
+		{"Union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"Intersection", a.Intersection(b), []int{3, 4}},
+		{"Difference", a.Difference(b), []int{1, 2}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []int{1, 2, 5}},
+		{"UnionWith", a.Clone().UnionWith(b), []int{1, 2, 3, 4, 5}},
+		{"UnionWithNil", KSet[int](nil).UnionWith(b), []int{3, 4, 5}},
+		{"IntersectWith", a.Clone().IntersectWith(b), []int{3, 4}},
+		{"DifferenceWith", a.Clone().DifferenceWith(b), []int{1, 2}},
+		{"SymmetricDifferenceWith", a.Clone().SymmetricDifferenceWith(b), []int{1, 2, 5}},
+		{"Deletes", a.Clone().Deletes(slices.Values([]int{1, 9})), []int{2, 3, 4}},
+		{"Retains", a.Clone().Retains(slices.Values([]int{4, 2, 9, 2})), []int{2, 4}},
+		{"UnionSeq", NewKSet[int]().Adds(a.UnionSeq(slices.Values([]int{9, 1, 9}))), []int{1, 2, 3, 4, 9}},
+		{"NotIn", NewKSet[int]().Adds(a.NotIn(slices.Values([]int{9, 1, 8}))), []int{8, 9}},
+	}
+	for _, tt := range tests {
+		if got := tt.got.Sorted(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v want %v", tt.name, got, tt.want)
+		}
+	}
+	if len(a) != 4 || len(b) != 3 {
+		t.Fatal("sources should not be changed")
+	}
+
+	if !NewKSet(3, 4).IsSubset(a) || a.IsSubset(b) || !a.IsSuperset(NewKSet(1)) || a.IsSuperset(b) {
+		t.Fatal("subset")
+	}
+	if a.Disjoint(b) || !a.Disjoint(NewKSet(7)) || !a.Disjoint(nil) || !KSet[int](nil).IsSubset(a) {
+		t.Fatal("disjoint")
+	}
+	if !a.ContainsAll(slices.Values([]int{1, 4})) || a.ContainsAll(slices.Values([]int{1, 5})) ||
+		!a.ContainsAny(slices.Values([]int{9, 4})) || a.ContainsAny(slices.Values([]int{9})) {
+		t.Fatal("contains")
+	}
+}
+
+func TestKSet_JSONAndSQL(t *testing.T) {
+	s := struct {
+		Ints  KSet[int]    `json:"ints"`
+		Strs  KSet[string] `json:"strs"`
+		Empty KSet[int]    `json:"empty"`
+		Nil   KSet[int]    `json:"nil"`
+	}{NewKSet(10, -1, 2), NewKSet("b", "a"), NewKSet[int](), nil}
+	bs, err := json.Marshal(s)
+	want := `{"ints":[-1,2,10],"strs":["a","b"],"empty":[],"nil":null}`
+	if err != nil || string(bs) != want {
+		t.Fatalf("marshal: %s %v", bs, err)
+	}
+	var back struct {
+		Ints KSet[int]    `json:"ints"`
+		Strs KSet[string] `json:"strs"`
+		Nil  KSet[int]    `json:"nil"`
+	}
+	if err = json.Unmarshal(bs, &back); err != nil || !back.Ints.Equal(s.Ints) || !back.Strs.Equal(s.Strs) || back.Nil != nil {
+		t.Fatalf("unmarshal: %v %v", back, err)
+	}
+	// the object form marshalled before KSet.MarshalJSON existed
+	if err = json.Unmarshal([]byte(`{"ints":{"2":{},"-1":{}},"strs":{"a":{}}}`), &back); err != nil ||
+		!slices.Equal(back.Ints.Sorted(), []int{-1, 2}) || !slices.Equal(back.Strs.Sorted(), []string{"a"}) {
+		t.Fatalf("unmarshal object: %v %v", back, err)
+	}
+
+	scans := []struct {
+		src  any
+		want []int
+	}{
+		{`[3,1,3]`, []int{1, 3}},
+		{` {"4":{}}`, []int{4}},
+		{[]byte(" 5, 2 ,5"), []int{2, 5}},
+		{"", []int{}},
+		{nil, nil},
+	}
+	for _, tt := range scans {
+		var ks KSet[int]
+		if err = ks.Scan(tt.src); err != nil {
+			t.Fatalf("scan %v: %v", tt.src, err)
+		}
+		if (tt.want == nil) != (ks == nil) || !slices.Equal(ks.Sorted(), tt.want) {
+			t.Fatalf("scan %v: %v", tt.src, ks)
+		}
+	}
+	var ks KSet[int]
+	if err = ks.Scan("1,x"); err == nil {
+		t.Fatal("invalid csv item should fail")
+	}
+	if v, err := NewKSet(3, 1).Value(); err != nil || string(v.([]byte)) != "[1,3]" {
+		t.Fatalf("value: %v %v", v, err)
+	}
+
+	var cs CSVSet[string]
+	if err = cs.Scan(`["x","y"]`); err != nil || len(cs) != 2 {
+		t.Fatalf("csv scan: %v", err)
+	}
+	cs = CSVSet[string](NewKSet("b", "a", "c"))
+	if v, err := cs.Value(); err != nil || v != "a,b,c" {
+		t.Fatalf("csv value: %v %v", v, err)
+	}
+	if _, err = CSVSet[string](NewKSet("a,b")).Value(); err == nil {
+		t.Fatal("comma in item should fail")
+	}
+}
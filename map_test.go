@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"encoding/json"
 	"fmt"
 	"maps"
 	"math/rand"
@@ -55,6 +56,103 @@ func TestOrderMap(t *testing.T) {
 	}
 }
 
+func TestOrderMap_Order(t *testing.T) {
+	m := NewOrderMap[string, int]()
+	m.Put("a", 1).Put("b", 2).Put("c", 3).Put("a", 10)
+	if v, _ := m.Get("a"); v != 1 {
+		t.Fatalf("Put should not change existing key, got %d", v)
+	}
+	m.Set("a", 10).Set("d", 4)
+	if v, _ := m.Get("a"); v != 10 || !slices.Equal(slices.Collect(m.Keys()), []string{"a", "b", "c", "d"}) {
+		t.Fatalf("Set failed: %v", slices.Collect(m.Keys()))
+	}
+
+	if !m.MoveToBack("a") || !m.MoveToFront("c") || !m.MoveBefore("d", "b") || !m.MoveAfter("c", "a") ||
+		m.MoveToFront("x") || m.MoveBefore("a", "x") {
+		t.Fatal("move failed")
+	}
+	if err := m.selfCheck(); err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{"d", "b", "a", "c"}
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, keys) {
+		t.Fatalf("keys: %v", got)
+	}
+	if got := slices.Collect(m.Values()); !slices.Equal(got, []int{4, 2, 10, 3}) {
+		t.Fatalf("values: %v", got)
+	}
+	var backward []string
+	for k := range m.Backward() {
+		backward = append(backward, k)
+	}
+	if !slices.Equal(backward, []string{"c", "a", "b", "d"}) {
+		t.Fatalf("backward: %v", backward)
+	}
+	var sorted []string
+	for k := range m.Sorted(func(a, b string) bool { return a < b }) {
+		sorted = append(sorted, k)
+	}
+	if !slices.Equal(sorted, []string{"a", "b", "c", "d"}) || !slices.Equal(slices.Collect(m.Keys()), keys) {
+		t.Fatalf("sorted: %v", sorted)
+	}
+
+	for i, k := range keys {
+		if key, _, ok := m.At(i); !ok || key != k {
+			t.Fatalf("At(%d) = %s", i, key)
+		}
+		if idx := m.IndexOf(k); idx != i {
+			t.Fatalf("IndexOf(%s) = %d", k, idx)
+		}
+	}
+	if k, v, ok := m.At(-1); !ok || k != "c" || v != 3 {
+		t.Fatalf("At(-1) = %s %d", k, v)
+	}
+	if _, _, ok := m.At(4); ok || m.IndexOf("x") != -1 {
+		t.Fatal("out of range")
+	}
+
+	c := m.Clone()
+	c.Set("e", 5)
+	c.MoveToFront("a")
+	if m.Len() != 4 || m.IndexOf("a") != 2 || c.Len() != 5 || c.IndexOf("a") != 0 {
+		t.Fatal("clone should be independent")
+	}
+}
+
+type testTextKey struct{ a, b int }
+
+func (k testTextKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d-%d", k.a, k.b)), nil
+}
+
+func TestOrderMap_MarshalJSON(t *testing.T) {
+	m := NewOrderMap[string, any]()
+	m.Put("z", 1).Put("a", []int{1, 2}).Put("<m>", nil)
+	bs, err := json.Marshal(m)
+	if err != nil || string(bs) != `{"z":1,"a":[1,2],"\u003cm\u003e":null}` {
+		t.Fatalf("%s %v", bs, err)
+	}
+
+	im := NewOrderMap[int8, string]()
+	im.Put(-3, "a").Put(1, "b")
+	tm := NewOrderMap[testTextKey, int]()
+	tm.Put(testTextKey{2, 1}, 1).Put(testTextKey{1, 2}, 2)
+	var nilMap *OrderMap[string, int]
+	s := struct {
+		I *OrderMap[int8, string]
+		T *OrderMap[testTextKey, int]
+		N *OrderMap[string, int]
+	}{im, tm, nilMap}
+	bs, err = json.Marshal(s)
+	if err != nil || string(bs) != `{"I":{"-3":"a","1":"b"},"T":{"2-1":1,"1-2":2},"N":null}` {
+		t.Fatalf("%s %v", bs, err)
+	}
+
+	if _, err = json.Marshal(NewOrderMap[float64, int]().Put(1.5, 1)); err == nil {
+		t.Fatal("float key should fail")
+	}
+}
+
 func TestKMap_RangeSubMap(t *testing.T) {
 	var km KMap[int, int]
 	size := 1000
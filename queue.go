@@ -0,0 +1,301 @@
+package tools
+
+import (
+	"container/heap"
+	"iter"
+)
+
+// PQItem is the handle of a value in a PriorityQueue, which can be used to update or remove the
+// value. Call PriorityQueue.Fix after changing Value directly.
+type PQItem[T any] struct {
+	Value T
+	index int // -1 if removed
+	pq    *PriorityQueue[T]
+}
+
+// PriorityQueue a heap ordered by less, the value with the highest priority (less than all others)
+// is popped first. Not thread safe.
+type PriorityQueue[T any] struct {
+	less  func(a, b T) bool
+	items []*PQItem[T]
+}
+
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{less: less}
+}
+
+// pqHeap implements heap.Interface
+type pqHeap[T any] PriorityQueue[T]
+
+func (h *pqHeap[T]) Len() int           { return len(h.items) }
+func (h *pqHeap[T]) Less(i, j int) bool { return h.less(h.items[i].Value, h.items[j].Value) }
+func (h *pqHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+func (h *pqHeap[T]) Push(x any) {
+	item := x.(*PQItem[T])
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+func (h *pqHeap[T]) Pop() any {
+	n := len(h.items) - 1
+	item := h.items[n]
+	h.items[n] = nil
+	h.items = h.items[:n]
+	item.index = -1
+	return item
+}
+
+func (pq *PriorityQueue[T]) heap() *pqHeap[T] { return (*pqHeap[T])(pq) }
+
+func (pq *PriorityQueue[T]) owns(item *PQItem[T]) bool {
+	return item != nil && item.pq == pq && item.index >= 0
+}
+
+func (pq *PriorityQueue[T]) Len() int { return len(pq.items) }
+
+// Push adds v and returns its handle
+func (pq *PriorityQueue[T]) Push(v T) *PQItem[T] {
+	item := &PQItem[T]{Value: v, pq: pq}
+	heap.Push(pq.heap(), item)
+	return item
+}
+
+func (pq *PriorityQueue[T]) Pop() (v T, ok bool) {
+	if len(pq.items) == 0 {
+		return v, false
+	}
+	return heap.Pop(pq.heap()).(*PQItem[T]).Value, true
+}
+
+func (pq *PriorityQueue[T]) Peek() (v T, ok bool) {
+	if len(pq.items) == 0 {
+		return v, false
+	}
+	return pq.items[0].Value, true
+}
+
+// Update changes the value of item, returns false if item is not in the queue
+func (pq *PriorityQueue[T]) Update(item *PQItem[T], v T) bool {
+	if !pq.owns(item) {
+		return false
+	}
+	item.Value = v
+	heap.Fix(pq.heap(), item.index)
+	return true
+}
+
+// Fix reorders item after its Value changed, returns false if item is not in the queue
+func (pq *PriorityQueue[T]) Fix(item *PQItem[T]) bool {
+	if !pq.owns(item) {
+		return false
+	}
+	heap.Fix(pq.heap(), item.index)
+	return true
+}
+
+// Remove removes item from the queue, returns false if item is not in the queue
+func (pq *PriorityQueue[T]) Remove(item *PQItem[T]) (v T, ok bool) {
+	if !pq.owns(item) {
+		return v, false
+	}
+	return heap.Remove(pq.heap(), item.index).(*PQItem[T]).Value, true
+}
+
+func (pq *PriorityQueue[T]) Clear() {
+	for _, item := range pq.items {
+		item.index = -1
+	}
+	pq.items = nil
+}
+
+// All iterates values in the queue in no particular order
+func (pq *PriorityQueue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range pq.items {
+			if !yield(item.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Drain pops and yields values in the order of priority until the queue is empty or the iteration
+// stops.
+func (pq *PriorityQueue[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := pq.Pop()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Deque a double-ended queue on a growable ring buffer, push and pop at both ends are O(1)
+// amortized. The zero value is an empty deque ready to use. Not thread safe.
+type Deque[T any] struct {
+	buf  []T
+	head int
+	n    int
+}
+
+func NewDeque[T any](capacity ...int) *Deque[T] {
+	return &Deque[T]{buf: make([]T, max(VariadicParam(capacity), 0))}
+}
+
+func (d *Deque[T]) Len() int { return d.n }
+
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+func (d *Deque[T]) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+	buf := make([]T, max(8, len(d.buf)*2))
+	for i := 0; i < d.n; i++ {
+		buf[i] = d.buf[d.index(i)]
+	}
+	d.buf, d.head = buf, 0
+}
+
+func (d *Deque[T]) PushBack(vs ...T) {
+	for _, v := range vs {
+		d.grow()
+		d.buf[d.index(d.n)] = v
+		d.n++
+	}
+}
+
+func (d *Deque[T]) PushFront(vs ...T) {
+	for _, v := range vs {
+		d.grow()
+		d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+		d.buf[d.head] = v
+		d.n++
+	}
+}
+
+func (d *Deque[T]) PopFront() (v T, ok bool) {
+	if d.n == 0 {
+		return v, false
+	}
+	var zero T
+	v, d.buf[d.head] = d.buf[d.head], zero
+	d.head = d.index(1)
+	d.n--
+	return v, true
+}
+
+func (d *Deque[T]) PopBack() (v T, ok bool) {
+	if d.n == 0 {
+		return v, false
+	}
+	var zero T
+	i := d.index(d.n - 1)
+	v, d.buf[i] = d.buf[i], zero
+	d.n--
+	return v, true
+}
+
+func (d *Deque[T]) Front() (v T, ok bool) { return d.At(0) }
+func (d *Deque[T]) Back() (v T, ok bool)  { return d.At(d.n - 1) }
+
+// At returns the i-th value from the front
+func (d *Deque[T]) At(i int) (v T, ok bool) {
+	if i < 0 || i >= d.n {
+		return v, false
+	}
+	return d.buf[d.index(i)], true
+}
+
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.n = 0, 0
+}
+
+// All iterates from the front to the back
+func (d *Deque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.n; i++ {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterates from the back to the front
+func (d *Deque[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := d.n - 1; i >= 0; i-- {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// RingPolicy decides what a full RingBuffer does when pushing
+type RingPolicy int
+
+const (
+	RingOverwrite RingPolicy = iota // the oldest value is dropped
+	RingReject                      // the new value is rejected
+)
+
+// RingBuffer a fixed capacity FIFO buffer. Not thread safe.
+type RingBuffer[T any] struct {
+	d      Deque[T]
+	policy RingPolicy
+}
+
+func NewRingBuffer[T any](capacity int, policy RingPolicy) *RingBuffer[T] {
+	if capacity <= 0 {
+		panic("tools: ring buffer capacity must be positive")
+	}
+	return &RingBuffer[T]{d: Deque[T]{buf: make([]T, capacity)}, policy: policy}
+}
+
+func (r *RingBuffer[T]) Len() int     { return r.d.n }
+func (r *RingBuffer[T]) Cap() int     { return len(r.d.buf) }
+func (r *RingBuffer[T]) IsFull() bool { return r.d.n == len(r.d.buf) }
+
+// Push appends v, returns false if v is rejected by a full buffer with RingReject
+func (r *RingBuffer[T]) Push(v T) bool {
+	if r.IsFull() {
+		if r.policy == RingReject {
+			return false
+		}
+		r.d.PopFront()
+	}
+	r.d.PushBack(v)
+	return true
+}
+
+// Pop removes and returns the oldest value
+func (r *RingBuffer[T]) Pop() (T, bool) { return r.d.PopFront() }
+
+// Peek returns the oldest value
+func (r *RingBuffer[T]) Peek() (T, bool) { return r.d.Front() }
+
+// Newest returns the latest pushed value
+func (r *RingBuffer[T]) Newest() (T, bool) { return r.d.Back() }
+
+func (r *RingBuffer[T]) Clear() { r.d.Clear() }
+
+// All iterates from the oldest to the newest
+func (r *RingBuffer[T]) All() iter.Seq[T] { return r.d.All() }
+
+func (r *RingBuffer[T]) Slice() []T {
+	ret := make([]T, 0, r.d.n)
+	for v := range r.d.All() {
+		ret = append(ret, v)
+	}
+	return ret
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	pq := NewPriorityQueue(func(a, b int) bool { return a < b })
+	items := make(map[int]*PQItem[int])
+	for _, v := range []int{5, 3, 8, 1, 9, 4} {
+		items[v] = pq.Push(v)
+	}
+	if v, _ := pq.Peek(); v != 1 || pq.Len() != 6 {
+		t.Fatalf("peek: %d", v)
+	}
+	if !pq.Update(items[9], 0) {
+		t.Fatal("update failed")
+	}
+	items[8].Value = 2
+	pq.Fix(items[8])
+	if v, ok := pq.Remove(items[3]); !ok || v != 3 {
+		t.Fatalf("remove: %d", v)
+	}
+	if _, ok := pq.Remove(items[3]); ok || pq.Update(items[3], 1) {
+		t.Fatal("removed item should not be accepted")
+	}
+	other := NewPriorityQueue(func(a, b int) bool { return a < b })
+	if other.Fix(items[5]) {
+		t.Fatal("item of another queue should not be accepted")
+	}
+	if got := slices.Collect(pq.Drain()); !slices.Equal(got, []int{0, 1, 2, 4, 5}) || pq.Len() != 0 {
+		t.Fatalf("drain: %v", got)
+	}
+
+	for i := 0; i < 200; i++ {
+		pq.Push(rand.Intn(50))
+	}
+	last := -1
+	for v := range pq.Drain() {
+		if v < last {
+			t.Fatalf("out of order: %d after %d", v, last)
+		}
+		last = v
+	}
+}
+
+func TestDeque(t *testing.T) {
+	var d Deque[int]
+	if _, ok := d.PopFront(); ok {
+		t.Fatal("pop from empty")
+	}
+	for i := 0; i < 20; i++ {
+		d.PushBack(i)
+		d.PushFront(-i - 1)
+	}
+	if d.Len() != 40 {
+		t.Fatalf("len: %d", d.Len())
+	}
+	if f, _ := d.Front(); f != -20 {
+		t.Fatalf("front: %d", f)
+	}
+	if b, _ := d.Back(); b != 19 {
+		t.Fatalf("back: %d", b)
+	}
+	all := slices.Collect(d.All())
+	if !slices.IsSorted(all) || all[0] != -20 {
+		t.Fatalf("all: %v", all)
+	}
+	backward := slices.Collect(d.Backward())
+	slices.Reverse(backward)
+	if !slices.Equal(all, backward) {
+		t.Fatal("backward")
+	}
+	for i := 0; i < 15; i++ {
+		d.PopFront()
+		d.PopBack()
+	}
+	if got := slices.Collect(d.All()); !slices.Equal(got, []int{-5, -4, -3, -2, -1, 0, 1, 2, 3, 4}) {
+		t.Fatalf("after pop: %v", got)
+	}
+	if v, ok := d.At(5); !ok || v != 0 {
+		t.Fatalf("at: %d", v)
+	}
+	d.Clear()
+	if _, ok := d.Back(); ok || d.Len() != 0 {
+		t.Fatal("clear")
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	r := NewRingBuffer[int](3, RingOverwrite)
+	for i := 1; i <= 5; i++ {
+		r.Push(i)
+	}
+	if !r.IsFull() || !slices.Equal(r.Slice(), []int{3, 4, 5}) {
+		t.Fatalf("overwrite: %v", r.Slice())
+	}
+	if v, _ := r.Pop(); v != 3 {
+		t.Fatalf("pop: %d", v)
+	}
+	r.Push(6)
+	if v, _ := r.Newest(); v != 6 || !slices.Equal(slices.Collect(r.All()), []int{4, 5, 6}) {
+		t.Fatalf("newest: %d", v)
+	}
+
+	rr := NewRingBuffer[string](2, RingReject)
+	if !rr.Push("a") || !rr.Push("b") || rr.Push("c") {
+		t.Fatal("reject policy")
+	}
+	if v, _ := rr.Peek(); v != "a" || rr.Cap() != 2 {
+		t.Fatalf("peek: %s", v)
+	}
+}
@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff decides the delay before the next attempt. attempt is the number of failed attempts
+// (starts from 1) and last is the previous delay (0 for the first retry). A negative delay, usually
+// from an overflow, makes Do wait as long as possible.
+type Backoff interface {
+	Delay(attempt int, last time.Duration) time.Duration
+}
+
+// BackoffFunc adapts a function to Backoff
+type BackoffFunc func(attempt int, last time.Duration) time.Duration
+
+func (f BackoffFunc) Delay(attempt int, last time.Duration) time.Duration { return f(attempt, last) }
+
+// Constant waits the same duration between attempts
+type Constant time.Duration
+
+func (c Constant) Delay(int, time.Duration) time.Duration { return time.Duration(c) }
+
+// Exponential waits Initial*Multiplier^(attempt-1), capped by Max. With Jitter in (0, 1], the
+// delay is randomized in [d*(1-Jitter), d].
+type Exponential struct {
+	Initial    time.Duration
+	Max        time.Duration  // 0 means no limit
+	Multiplier float64        // <=1 means 2
+	Jitter     float64        // fraction of the delay to randomize, 0 means no jitter
+	Rand       func() float64 // returns a number in [0, 1), nil means math/rand/v2
+}
+
+func (e Exponential) Delay(attempt int, _ time.Duration) time.Duration {
+	if e.Initial <= 0 {
+		return 0
+	}
+	m := e.Multiplier
+	if m <= 1 {
+		m = 2
+	}
+	d := float64(e.Initial) * math.Pow(m, float64(max(attempt, 1)-1))
+	if e.Max > 0 && d > float64(e.Max) {
+		d = float64(e.Max)
+	}
+	// math.Pow returns +Inf for large attempts, which makes NaN with jitter
+	if d >= math.MaxInt64 {
+		d = math.MaxInt64
+	}
+	if e.Jitter > 0 {
+		d -= d * min(e.Jitter, 1) * random(e.Rand)
+	}
+	if d >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// DecorrelatedJitter waits a random duration in [Base, last*3], capped by Max, which spreads
+// retries of many clients better than Exponential.
+type DecorrelatedJitter struct {
+	Base time.Duration
+	Max  time.Duration  // 0 means no limit
+	Rand func() float64 // returns a number in [0, 1), nil means math/rand/v2
+}
+
+func (j DecorrelatedJitter) Delay(_ int, last time.Duration) time.Duration {
+	if j.Base <= 0 {
+		return 0
+	}
+	upper := max(last*3, j.Base)
+	if last > math.MaxInt64/3 {
+		upper = math.MaxInt64
+	}
+	// float rounding may push the sum to 2^63
+	f := float64(j.Base) + float64(upper-j.Base)*random(j.Rand)
+	d := time.Duration(math.MaxInt64)
+	if f < math.MaxInt64 {
+		d = time.Duration(f)
+	}
+	if j.Max > 0 && d > j.Max {
+		d = j.Max
+	}
+	return d
+}
+
+func random(r func() float64) float64 {
+	if r != nil {
+		return r()
+	}
+	return rand.Float64()
+}
@@ -0,0 +1,224 @@
+// Package retry runs operations again on failure with backoff policies.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stephenfire/go-tools/log"
+)
+
+const DefaultMaxAttempts = 3
+
+var (
+	ErrMaxAttempts = errors.New("retry: max attempts reached")
+	ErrMaxElapsed  = errors.New("retry: max elapsed time reached")
+)
+
+// Clock is the time source of retries, which can be replaced in tests
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+var SystemClock Clock = systemClock{}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not retryable
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// DefaultRetryable retries all errors except permanent ones and errors of context
+func DefaultRetryable(err error) bool {
+	return !IsPermanent(err) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Attempt describes a failed attempt passed to hooks
+type Attempt struct {
+	Name    string
+	Number  int           // starts from 1
+	Err     error         // the error of this attempt
+	Elapsed time.Duration // time since the first attempt started
+	Delay   time.Duration // the delay before the next attempt, 0 if Final
+	Final   bool          // no more attempts
+}
+
+type Hook func(a Attempt)
+
+// LogHook logs failed attempts through logger, or the root logger of the log package if logger is
+// not given. Retries are logged at level and the final failure at logrus.WarnLevel or higher.
+func LogHook(level logrus.Level, logger ...logrus.FieldLogger) Hook {
+	return func(a Attempt) {
+		fields := logrus.Fields{"attempt": a.Number, "elapsed": a.Elapsed, "error": a.Err}
+		if a.Name != "" {
+			fields["name"] = a.Name
+		}
+		var l logrus.FieldLogger
+		if len(logger) > 0 && logger[0] != nil {
+			l = logger[0].WithFields(fields)
+		} else {
+			l = log.WithFields(fields)
+		}
+		lvl := level
+		if a.Final {
+			lvl = min(lvl, logrus.WarnLevel)
+		} else {
+			l = l.WithField("delay", a.Delay)
+		}
+		msg := "retrying"
+		if a.Final {
+			msg = "giving up"
+		}
+		if entry, ok := l.(*logrus.Entry); ok {
+			entry.Log(lvl, msg)
+			return
+		}
+		switch {
+		case lvl <= logrus.ErrorLevel:
+			l.Error(msg)
+		case lvl == logrus.WarnLevel:
+			l.Warn(msg)
+		case lvl == logrus.InfoLevel:
+			l.Info(msg)
+		default:
+			l.Debug(msg)
+		}
+	}
+}
+
+// Policy how to retry an operation
+type Policy struct {
+	Name string // used in logs
+	// MaxAttempts is the max number of attempts including the first one, 0 means
+	// DefaultMaxAttempts and negative means no limit.
+	MaxAttempts int
+	// MaxElapsed stops retrying if the next attempt would start after MaxElapsed since the first
+	// attempt, 0 means no limit.
+	MaxElapsed time.Duration
+	Backoff    Backoff          // nil means Exponential{Initial: 100ms, Max: 10s, Jitter: 0.2}
+	Retryable  func(error) bool // nil means DefaultRetryable
+	Clock      Clock            // nil means SystemClock
+	Hooks      []Hook           // called after every failed attempt
+}
+
+var defaultBackoff = Exponential{Initial: 100 * time.Millisecond, Max: 10 * time.Second, Jitter: 0.2}
+
+// Error returned when retrying stopped, which wraps both the error of the last attempt and the
+// reason (ErrMaxAttempts, ErrMaxElapsed or the error of context, nil if the error is not retryable).
+type Error struct {
+	Attempts int
+	Err      error
+	Reason   error
+}
+
+func (e *Error) Error() string {
+	if e.Reason == nil {
+		return fmt.Sprintf("retry: failed after %d attempts: %v", e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("retry: failed after %d attempts (%v): %v", e.Attempts, e.Reason, e.Err)
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Reason == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Reason}
+}
+
+// Do runs op until it succeeds or retrying is stopped, see Error
+func Do(ctx context.Context, p Policy, op func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	})
+	return err
+}
+
+// DoValue is Do for operations with a result
+func DoValue[T any](ctx context.Context, p Policy, op func(ctx context.Context) (T, error)) (T, error) {
+	clock, backoff, retryable := p.Clock, p.Backoff, p.Retryable
+	if clock == nil {
+		clock = SystemClock
+	}
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	maxAttempts := p.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	var zero T
+	start := clock.Now()
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return zero, &Error{Attempts: attempt - 1, Err: err, Reason: err}
+		}
+		v, err := op(ctx)
+		if err == nil {
+			return v, nil
+		}
+		elapsed := clock.Now().Sub(start)
+		var reason error
+		canRetry := retryable(err)
+		switch {
+		case !canRetry:
+		case maxAttempts > 0 && attempt >= maxAttempts:
+			reason = ErrMaxAttempts
+		default:
+			delay = backoff.Delay(attempt, delay)
+			if delay < 0 {
+				// overflowed, wait as long as possible instead of retrying immediately
+				delay = math.MaxInt64
+			}
+			if p.MaxElapsed > 0 && delay > p.MaxElapsed-elapsed {
+				reason = ErrMaxElapsed
+			}
+		}
+		final := reason != nil || !canRetry
+		a := Attempt{Name: p.Name, Number: attempt, Err: err, Elapsed: elapsed, Final: final}
+		if !final {
+			a.Delay = delay
+		}
+		for _, hook := range p.Hooks {
+			hook(a)
+		}
+		if final {
+			return zero, &Error{Attempts: attempt, Err: err, Reason: reason}
+		}
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return zero, &Error{Attempts: attempt, Err: err, Reason: ctx.Err()}
+			case <-clock.After(delay):
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeClock advances immediately when waiting
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestBackoff(t *testing.T) {
+	half := func() float64 { return 0.5 }
+	tests := []struct {
+		name    string
+		backoff Backoff
+		want    []time.Duration
+	}{
+		{"Constant", Constant(time.Second), []time.Duration{time.Second, time.Second, time.Second}},
+		{"Exponential", Exponential{Initial: 100 * time.Millisecond, Max: time.Second},
+			[]time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}},
+		{"ExponentialJitter", Exponential{Initial: time.Second, Multiplier: 3, Jitter: 0.5, Rand: half},
+			[]time.Duration{750 * time.Millisecond, 2250 * time.Millisecond}},
+		{"DecorrelatedJitter", DecorrelatedJitter{Base: time.Second, Max: 5 * time.Second, Rand: half},
+			[]time.Duration{time.Second, 2 * time.Second, 3500 * time.Millisecond, 5 * time.Second}},
+	}
+	for _, tt := range tests {
+		var last time.Duration
+		for i, want := range tt.want {
+			last = tt.backoff.Delay(i+1, last)
+			if last != want {
+				t.Errorf("%s attempt %d: got %s want %s", tt.name, i+1, last, want)
+			}
+		}
+	}
+	// float64(MaxInt64-Base) rounds up, a random number at the upper bound makes the sum overflow
+	one := func() float64 { return 1 }
+	if d := (DecorrelatedJitter{Base: time.Second, Rand: one}).Delay(100, math.MaxInt64); d != math.MaxInt64 {
+		t.Fatalf("decorrelated jitter overflow: %s", d)
+	}
+	if d := (DecorrelatedJitter{Base: -time.Second, Rand: half}).Delay(1, 0); d != 0 {
+		t.Fatalf("negative base: %s", d)
+	}
+	for _, attempt := range []int{64, 1000, 1100} {
+		e := Exponential{Initial: time.Hour, Jitter: 0.2, Rand: half}
+		if d := e.Delay(attempt, 0); d < math.MaxInt64/10*9 {
+			t.Fatalf("overflow at attempt %d: %s", attempt, d)
+		}
+		if d := (Exponential{Initial: time.Hour}).Delay(attempt, 0); d != math.MaxInt64 {
+			t.Fatalf("overflow at attempt %d: %s", attempt, d)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	errTemp := errors.New("temporary")
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var attempts []Attempt
+	p := Policy{
+		MaxAttempts: 5,
+		Backoff:     Constant(time.Second),
+		Clock:       clock,
+		Hooks:       []Hook{func(a Attempt) { attempts = append(attempts, a) }},
+	}
+
+	calls := 0
+	v, err := DoValue(context.Background(), p, func(ctx context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errTemp
+		}
+		return 42, nil
+	})
+	if err != nil || v != 42 || len(clock.sleeps) != 2 || len(attempts) != 2 || attempts[1].Elapsed != time.Second {
+		t.Fatalf("success after retries: %d %v %v", v, err, attempts)
+	}
+
+	attempts = nil
+	err = Do(context.Background(), p, func(ctx context.Context) error { return errTemp })
+	var re *Error
+	if !errors.As(err, &re) || re.Attempts != 5 || !errors.Is(err, ErrMaxAttempts) || !errors.Is(err, errTemp) {
+		t.Fatalf("max attempts: %v", err)
+	}
+	if len(attempts) != 5 || !attempts[4].Final || attempts[4].Delay != 0 || attempts[3].Final {
+		t.Fatalf("hooks: %+v", attempts)
+	}
+
+	p.MaxAttempts = -1
+	p.MaxElapsed = 3500 * time.Millisecond
+	err = Do(context.Background(), p, func(ctx context.Context) error { return errTemp })
+	if !errors.As(err, &re) || re.Attempts != 4 || !errors.Is(err, ErrMaxElapsed) {
+		t.Fatalf("max elapsed: %v", err)
+	}
+
+	// an overflowed negative delay stops retrying by MaxElapsed instead of a busy loop
+	p.Backoff = BackoffFunc(func(int, time.Duration) time.Duration { return math.MinInt64 })
+	err = Do(context.Background(), p, func(ctx context.Context) error { return errTemp })
+	if !errors.As(err, &re) || re.Attempts != 1 || !errors.Is(err, ErrMaxElapsed) {
+		t.Fatalf("negative delay: %v", err)
+	}
+	p.Backoff = Constant(time.Second)
+
+	calls = 0
+	errBad := errors.New("bad request")
+	err = Do(context.Background(), p, func(ctx context.Context) error {
+		calls++
+		return Permanent(errBad)
+	})
+	if !errors.As(err, &re) || calls != 1 || re.Reason != nil || !errors.Is(err, errBad) {
+		t.Fatalf("permanent: %v", err)
+	}
+	p.Retryable = func(err error) bool { return errors.Is(err, errTemp) }
+	calls = 0
+	if err = Do(context.Background(), p, func(ctx context.Context) error { calls++; return errBad }); calls != 1 {
+		t.Fatalf("retryable: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = Do(ctx, Policy{MaxAttempts: -1, Backoff: Constant(time.Hour)}, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errTemp
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errTemp) || calls != 1 {
+		t.Fatalf("cancelled: %v", err)
+	}
+}
+
+func TestLogHook(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := logrus.New()
+	logger.SetOutput(buf)
+	logger.SetLevel(logrus.DebugLevel)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	p := Policy{
+		Name:        "load-user",
+		MaxAttempts: 2,
+		Backoff:     Constant(time.Millisecond),
+		Clock:       &fakeClock{},
+		Hooks:       []Hook{LogHook(logrus.InfoLevel, logger)},
+	}
+	_ = Do(context.Background(), p, func(ctx context.Context) error { return errors.New("timeout") })
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 ||
+		!strings.Contains(lines[0], "level=info msg=retrying attempt=1 delay=1ms") ||
+		!strings.Contains(lines[1], "level=warning msg=\"giving up\" attempt=2") ||
+		!strings.Contains(lines[1], "name=load-user") {
+		t.Fatalf("logs:\n%s", buf.String())
+	}
+}
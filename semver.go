@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"cmp"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidSemVer = errors.New("tools: invalid semantic version")
+	// ErrVersionNotRepresentable is returned when a SemVer cannot be converted to Version losslessly
+	ErrVersionNotRepresentable = errors.New("tools: semantic version not representable by Version")
+)
+
+// SemVerAlpha the prerelease identifier of alpha Version (1.2.3.a <-> 1.2.3-alpha)
+const SemVerAlpha = "alpha"
+
+// SemVer Semantic Versioning 2.0.0: major.minor.patch[-prerelease][+build], see https://semver.org
+type SemVer struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string // dot separated identifiers after '-'
+	Build      []string // dot separated identifiers after '+', ignored in precedence
+}
+
+// ParseSemVer parses a semantic version, a leading "v" is allowed
+func ParseSemVer(s string) (SemVer, error) {
+	str := strings.TrimSpace(s)
+	if len(str) > 0 && (str[0] == 'v' || str[0] == 'V') {
+		str = str[1:]
+	}
+	invalid := func(reason string) (SemVer, error) {
+		return SemVer{}, fmt.Errorf("%w %q: %s", ErrInvalidSemVer, s, reason)
+	}
+	var sv SemVer
+	var ok bool
+	var build, pre string
+	if str, build, ok = strings.Cut(str, "+"); ok {
+		if sv.Build, ok = splitSemVerIdentifiers(build, false); !ok {
+			return invalid("invalid build metadata")
+		}
+	}
+	if str, pre, ok = strings.Cut(str, "-"); ok {
+		if sv.Prerelease, ok = splitSemVerIdentifiers(pre, true); !ok {
+			return invalid("invalid prerelease")
+		}
+	}
+	parts := strings.Split(str, ".")
+	if len(parts) != 3 {
+		return invalid("should be major.minor.patch")
+	}
+	nums := [3]*uint64{&sv.Major, &sv.Minor, &sv.Patch}
+	for i, p := range parts {
+		if !isSemVerNumeric(p) {
+			return invalid("invalid number " + strconv.Quote(p))
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return invalid(err.Error())
+		}
+		*nums[i] = n
+	}
+	return sv, nil
+}
+
+func MustParseSemVer(s string) SemVer {
+	sv, err := ParseSemVer(s)
+	if err != nil {
+		panic(err)
+	}
+	return sv
+}
+
+func isSemVerNumeric(s string) bool {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isSemVerDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// splitSemVerIdentifiers splits and checks dot separated identifiers, numeric identifiers of
+// prerelease must not include leading zeros
+func splitSemVerIdentifiers(s string, prerelease bool) ([]string, bool) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, false
+		}
+		for i := 0; i < len(id); i++ {
+			c := id[i]
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+				return nil, false
+			}
+		}
+		if prerelease && isSemVerDigits(id) && !isSemVerNumeric(id) {
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+func (s SemVer) String() string {
+	buf := new(strings.Builder)
+	buf.WriteString(strconv.FormatUint(s.Major, 10))
+	buf.WriteByte('.')
+	buf.WriteString(strconv.FormatUint(s.Minor, 10))
+	buf.WriteByte('.')
+	buf.WriteString(strconv.FormatUint(s.Patch, 10))
+	if len(s.Prerelease) > 0 {
+		buf.WriteByte('-')
+		buf.WriteString(strings.Join(s.Prerelease, "."))
+	}
+	if len(s.Build) > 0 {
+		buf.WriteByte('+')
+		buf.WriteString(strings.Join(s.Build, "."))
+	}
+	return buf.String()
+}
+
+func (s SemVer) IsPrerelease() bool { return len(s.Prerelease) > 0 }
+
+// Compare returns -1, 0 or 1 by the precedence of semantic versions: major, minor and patch are
+// compared numerically, a prerelease version has lower precedence than the normal version, and
+// prerelease identifiers are compared one by one (numeric ones numerically and lower than
+// alphanumeric ones). Build metadata is ignored.
+func (s SemVer) Compare(o SemVer) int {
+	if c := cmp.Compare(s.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(s.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(s.Patch, o.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(s.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(s.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(s.Prerelease) && i < len(o.Prerelease); i++ {
+		a, b := s.Prerelease[i], o.Prerelease[i]
+		an, bn := isSemVerDigits(a), isSemVerDigits(b)
+		var c int
+		switch {
+		case an && bn:
+			// without leading zeros, longer numbers are larger
+			c = cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+		case an:
+			c = -1
+		case bn:
+			c = 1
+		default:
+			c = strings.Compare(a, b)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(s.Prerelease), len(o.Prerelease))
+}
+
+func (s SemVer) Less(o SemVer) bool { return s.Compare(o) < 0 }
+
+// Equal reports whether s and o have the same precedence, build metadata is ignored
+func (s SemVer) Equal(o SemVer) bool { return s.Compare(o) == 0 }
+
+// Version converts to Version, only versions without build metadata and with no prerelease or the
+// only "alpha" prerelease identifier can be converted.
+func (s SemVer) Version() (Version, error) {
+	if len(s.Build) > 0 {
+		return 0, fmt.Errorf("%w: %s has build metadata", ErrVersionNotRepresentable, s)
+	}
+	alpha := len(s.Prerelease) > 0
+	if alpha && !slices.Equal(s.Prerelease, []string{SemVerAlpha}) {
+		return 0, fmt.Errorf("%w: %s has prerelease other than %s", ErrVersionNotRepresentable, s, SemVerAlpha)
+	}
+	v, err := NewVersion(s.Major, s.Minor, s.Patch, alpha)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", ErrVersionNotRepresentable, s, err)
+	}
+	return v, nil
+}
+
+// SemVer converts to SemVer, alpha versions have the prerelease "alpha"
+func (v Version) SemVer() SemVer {
+	s := SemVer{Major: v.Major(), Minor: v.Minor(), Patch: v.Patch()}
+	if v < 0 {
+		s.Prerelease = []string{SemVerAlpha}
+	}
+	return s
+}
+
+func (s SemVer) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *SemVer) UnmarshalText(text []byte) error {
+	v, err := ParseSemVer(string(text))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+func (s *SemVer) Scan(value any) error {
+	if s == nil {
+		return ErrNilValue
+	}
+	switch v := value.(type) {
+	case nil:
+		return ErrNilSource
+	case []byte:
+		return s.UnmarshalText(v)
+	case string:
+		return s.UnmarshalText([]byte(v))
+	default:
+		return fmt.Errorf("tools: SemVer scan source was not []byte or string but %T", value)
+	}
+}
+
+func (s SemVer) Value() (driver.Value, error) {
+	return s.String(), nil
+}
+
+type NullSemVer = Null[SemVer]
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"v1.2.3-rc.1+build.5", "1.2.3-rc.1+build.5", false},
+		{"1.0.0-alpha-1.x-y.0", "1.0.0-alpha-1.x-y.0", false},
+		{"1.0.0+001", "1.0.0+001", false},
+		{"1.0.0-01", "", true},
+		{"01.0.0", "", true},
+		{"1.0", "", true},
+		{"1.0.0-", "", true},
+		{"1.0.0-a..b", "", true},
+		{"1.0.0+a_b", "", true},
+		{"1.0.0.a", "", true},
+	}
+	for _, test := range tests {
+		sv, err := ParseSemVer(test.in)
+		if (err != nil) != test.err {
+			t.Fatalf("%s: error %v", test.in, err)
+		}
+		if err != nil {
+			if !errors.Is(err, ErrInvalidSemVer) {
+				t.Fatalf("%s: %v", test.in, err)
+			}
+			continue
+		}
+		if sv.String() != test.want {
+			t.Fatalf("%s: got %s", test.in, sv)
+		}
+	}
+}
+
+func TestSemVer_Compare(t *testing.T) {
+	// in ascending order, from semver.org
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0", "10.0.0"}
+	svs := make([]SemVer, len(ordered))
+	for i, s := range ordered {
+		svs[i] = MustParseSemVer(s)
+	}
+	shuffled := slices.Clone(svs)
+	slices.Reverse(shuffled)
+	slices.SortFunc(shuffled, SemVer.Compare)
+	for i := range svs {
+		if shuffled[i].String() != ordered[i] {
+			t.Fatalf("sorted %d: got %s, want %s", i, shuffled[i], ordered[i])
+		}
+		if i > 0 && !svs[i-1].Less(svs[i]) {
+			t.Fatalf("%s should less than %s", svs[i-1], svs[i])
+		}
+	}
+	if !MustParseSemVer("1.0.0+a").Equal(MustParseSemVer("1.0.0+b")) {
+		t.Fatal("build metadata should be ignored")
+	}
+}
+
+func TestSemVer_Version(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+		err  bool
+	}{
+		{"1.2.3", 1_000_002_000_003, false},
+		{"1.2.3-alpha", -1_000_002_000_003, false},
+		{"0.0.0", 0, false},
+		{"0.0.0-alpha", 0, true},
+		{"1.2.3-rc.1", 0, true},
+		{"1.2.3+b", 0, true},
+		{"1000000.0.0", 0, true},
+	}
+	for _, test := range tests {
+		v, err := MustParseSemVer(test.in).Version()
+		if (err != nil) != test.err || v != test.want {
+			t.Fatalf("%s: got %d %v", test.in, v, err)
+		}
+		if err != nil {
+			if !errors.Is(err, ErrVersionNotRepresentable) {
+				t.Fatalf("%s: %v", test.in, err)
+			}
+			continue
+		}
+		if back := v.SemVer().String(); back != test.in {
+			t.Fatalf("%s: round trip got %s", test.in, back)
+		}
+	}
+}
+
+func TestSemVer_SQLAndJSON(t *testing.T) {
+	sv := MustParseSemVer("1.2.3-rc.1+build.5")
+	bs, err := json.Marshal(map[string]any{"v": sv, "n": NullSemVer{}})
+	if err != nil || string(bs) != `{"n":null,"v":"1.2.3-rc.1+build.5"}` {
+		t.Fatalf("marshal: %s %v", bs, err)
+	}
+	var got struct {
+		V SemVer     `json:"v"`
+		N NullSemVer `json:"n"`
+	}
+	if err = json.Unmarshal([]byte(`{"v":"1.2.3-rc.1+build.5","n":"2.0.0"}`), &got); err != nil ||
+		got.V.String() != sv.String() || !got.N.Valid || got.N.V.String() != "2.0.0" {
+		t.Fatalf("unmarshal: %+v %v", got, err)
+	}
+	if dv, err := sv.Value(); err != nil || dv != "1.2.3-rc.1+build.5" {
+		t.Fatalf("value: %v %v", dv, err)
+	}
+	var scanned SemVer
+	if err = scanned.Scan([]byte("3.0.0-beta")); err != nil || scanned.String() != "3.0.0-beta" {
+		t.Fatalf("scan: %s %v", scanned, err)
+	}
+	if err = scanned.Scan(nil); !errors.Is(err, ErrNilSource) {
+		t.Fatalf("scan nil: %v", err)
+	}
+	var ns NullSemVer
+	if err = ns.Scan("1.0.0"); err != nil || !ns.Valid || ns.V.String() != "1.0.0" {
+		t.Fatalf("scan null: %+v %v", ns, err)
+	}
+}
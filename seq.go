@@ -0,0 +1,274 @@
+package tools
+
+import (
+	"iter"
+)
+
+// SeqMap converts every element of seq by f
+func SeqMap[T, R any](seq iter.Seq[T], f func(T) R) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		for t := range seq {
+			if !yield(f(t)) {
+				return
+			}
+		}
+	}
+}
+
+func SeqFilter[T any](seq iter.Seq[T], pred func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for t := range seq {
+			if pred(t) && !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+func SeqFilter2[K, V any](seq iter.Seq2[K, V], pred func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if pred(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+func SeqKeys[K, V any](seq iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func SeqValues[K, V any](seq iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range seq {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SeqFlatMap concatenates the sequences converted from every element of seq
+func SeqFlatMap[T, R any](seq iter.Seq[T], f func(T) iter.Seq[R]) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		for t := range seq {
+			for r := range f(t) {
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SeqTake yields the first n elements at most
+func SeqTake[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for t := range seq {
+			if !yield(t) {
+				return
+			}
+			i++
+			if i >= n {
+				return
+			}
+		}
+	}
+}
+
+// SeqSkip skips the first n elements
+func SeqSkip[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := 0
+		for t := range seq {
+			if i < n {
+				i++
+				continue
+			}
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+// SeqChunk yields batches with size elements at most like BatchCall, size <= 0 means 100. Every
+// batch is a new slice.
+func SeqChunk[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size <= 0 {
+		size = 100
+	}
+	return func(yield func([]T) bool) {
+		var batch []T
+		for t := range seq {
+			if batch == nil {
+				batch = make([]T, 0, size)
+			}
+			batch = append(batch, t)
+			if len(batch) >= size {
+				if !yield(batch) {
+					return
+				}
+				batch = nil
+			}
+		}
+		if len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// SeqWindow yields sliding windows with size elements, every window is a new slice. Nothing is
+// yielded if seq has less than size elements.
+func SeqWindow[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if size <= 0 {
+			return
+		}
+		window := make([]T, 0, size)
+		for t := range seq {
+			if len(window) == size {
+				window = window[1:]
+			}
+			window = append(window, t)
+			if len(window) == size {
+				if !yield(append([]T(nil), window...)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SeqZip pairs elements of a and b in order, stops at the end of the shorter one
+func SeqZip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		next, stop := iter.Pull(b)
+		defer stop()
+		for va := range a {
+			vb, ok := next()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// SeqEnumerate yields elements with their indexes starting from 0
+func SeqEnumerate[T any](seq iter.Seq[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for t := range seq {
+			if !yield(i, t) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// SeqDistinct yields the first occurrence of every element
+func SeqDistinct[T comparable](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(KSet[T])
+		for t := range seq {
+			if seen.IsExist(t) {
+				continue
+			}
+			seen.Add(t)
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+func SeqReduce[T, R any](seq iter.Seq[T], initial R, f func(R, T) R) R {
+	r := initial
+	for t := range seq {
+		r = f(r, t)
+	}
+	return r
+}
+
+// SeqGroupBy groups elements of seq by key, see GroupBy
+func SeqGroupBy[T any, K comparable](seq iter.Seq[T], key func(T) K) MultiMap[K, T] {
+	m := make(MultiMap[K, T])
+	for t := range seq {
+		m.Put(key(t), t)
+	}
+	return m
+}
+
+// SeqMerge merges sequences sorted by compare into one sorted sequence, elements with the same order
+// are yielded in the order of seqs.
+func SeqMerge[T any](compare func(a, b T) int, seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		type head struct {
+			next func() (T, bool)
+			v    T
+		}
+		heads := make([]*head, 0, len(seqs))
+		for _, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop()
+			if v, ok := next(); ok {
+				heads = append(heads, &head{next: next, v: v})
+			}
+		}
+		for len(heads) > 0 {
+			mi := 0
+			for i := 1; i < len(heads); i++ {
+				if compare(heads[i].v, heads[mi].v) < 0 {
+					mi = i
+				}
+			}
+			if !yield(heads[mi].v) {
+				return
+			}
+			if v, ok := heads[mi].next(); ok {
+				heads[mi].v = v
+			} else {
+				heads = append(heads[:mi], heads[mi+1:]...)
+			}
+		}
+	}
+}
+
+func CollectKS[T comparable](seq iter.Seq[T]) KS[T] {
+	var ks KS[T]
+	for t := range seq {
+		ks = append(ks, t)
+	}
+	return ks
+}
+
+func CollectKSet[K comparable](seq iter.Seq[K]) KSet[K] {
+	return make(KSet[K]).Adds(seq)
+}
+
+// CollectKMap collects pairs into a KMap, the later value overwrites the former one
+func CollectKMap[K comparable, V any](seq iter.Seq2[K, V]) KMap[K, V] {
+	return make(KMap[K, V]).Puts(seq)
+}
+
+// CollectOrderMap collects pairs into an OrderMap, the later value overwrites the former one and
+// keeps the position of the first one.
+func CollectOrderMap[K comparable, V any](seq iter.Seq2[K, V]) *OrderMap[K, V] {
+	m := NewOrderMap[K, V]()
+	for k, v := range seq {
+		m.Set(k, v)
+	}
+	return m
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestSeqCombinators(t *testing.T) {
+	nums := slices.Values([]int{1, 2, 3, 4, 5, 6, 7})
+	even := func(i int) bool { return i%2 == 0 }
+
+	tests := []struct {
+		name string
+		got  []int
+		want []int
+	}{
+		{"Map", slices.Collect(SeqMap(nums, func(i int) int { return i * 10 })), []int{10, 20, 30, 40, 50, 60, 70}},
+		{"Filter", slices.Collect(SeqFilter(nums, even)), []int{2, 4, 6}},
+		{"FlatMap", slices.Collect(SeqFlatMap(SeqTake(nums, 3), func(i int) iter.Seq[int] {
+			return slices.Values(slices.Repeat([]int{i}, i))
+		})), []int{1, 2, 2, 3, 3, 3}},
+		{"Take", slices.Collect(SeqTake(nums, 2)), []int{1, 2}},
+		{"TakeZero", slices.Collect(SeqTake(nums, 0)), nil},
+		{"Skip", slices.Collect(SeqSkip(nums, 5)), []int{6, 7}},
+		{"SkipTake", slices.Collect(SeqTake(SeqSkip(nums, 1), 2)), []int{2, 3}},
+		{"Distinct", slices.Collect(SeqDistinct(slices.Values([]int{3, 1, 3, 2, 1}))), []int{3, 1, 2}},
+		{"Merge", slices.Collect(SeqMerge(cmp.Compare[int],
+			slices.Values([]int{1, 4, 9}), slices.Values([]int{}), slices.Values([]int{2, 3, 10}), slices.Values([]int{4}))),
+			[]int{1, 2, 3, 4, 4, 9, 10}},
+		{"MergeTake", slices.Collect(SeqTake(SeqMerge(cmp.Compare[int], nums, nums), 3)), []int{1, 1, 2}},
+	}
+	for _, tt := range tests {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("%s: got %v want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	chunks := slices.Collect(SeqChunk(nums, 3))
+	if len(chunks) != 3 || !slices.Equal(chunks[2], []int{7}) || !slices.Equal(chunks[1], []int{4, 5, 6}) {
+		t.Fatalf("chunk: %v", chunks)
+	}
+	windows := slices.Collect(SeqWindow(SeqTake(nums, 4), 3))
+	if len(windows) != 2 || !slices.Equal(windows[0], []int{1, 2, 3}) || !slices.Equal(windows[1], []int{2, 3, 4}) {
+		t.Fatalf("window: %v", windows)
+	}
+	if len(slices.Collect(SeqWindow(SeqTake(nums, 2), 3))) != 0 {
+		t.Fatal("short window")
+	}
+
+	zipped := maps.Collect(SeqZip(SeqMap(nums, strconv.Itoa), slices.Values([]bool{true, false})))
+	if len(zipped) != 2 || !zipped["1"] || zipped["2"] {
+		t.Fatalf("zip: %v", zipped)
+	}
+	for i, v := range SeqEnumerate(SeqSkip(nums, 2)) {
+		if v != i+3 {
+			t.Fatalf("enumerate: %d %d", i, v)
+		}
+	}
+	if sum := SeqReduce(nums, "", func(s string, i int) string { return s + strconv.Itoa(i) }); sum != "1234567" {
+		t.Fatalf("reduce: %s", sum)
+	}
+	groups := SeqGroupBy(nums, even)
+	if !slices.Equal(groups.Get(true), []int{2, 4, 6}) || len(groups.Get(false)) != 4 {
+		t.Fatalf("group by: %v", groups)
+	}
+
+	m := NewOrderMap[string, int]()
+	m.Put("b", 2).Put("a", 1).Put("c", 3)
+	filtered := SeqFilter2(m.All(), func(k string, v int) bool { return v != 1 })
+	if ks := CollectKS(SeqKeys(filtered)); !ks.Equal(KS[string]{"b", "c"}) {
+		t.Fatalf("keys: %v", ks)
+	}
+	if s := CollectKSet(SeqValues(m.All())); !s.Equal(NewKSet(1, 2, 3)) {
+		t.Fatalf("kset: %v", s)
+	}
+	if km := CollectKMap(filtered); len(km) != 2 || km["c"] != 3 {
+		t.Fatalf("kmap: %v", km)
+	}
+	om := CollectOrderMap(SeqZip(slices.Values([]string{"x", "y", "x"}), nums))
+	if v, _ := om.Get("x"); v != 3 || !slices.Equal(slices.Collect(om.Keys()), []string{"x", "y"}) {
+		t.Fatalf("order map: %v", slices.Collect(om.Keys()))
+	}
+}
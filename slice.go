@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+)
+
+// SortBy sorts s stably in ascending order of key, key is called once for each element
+func SortBy[S ~[]T, T any, O cmp.Ordered](s S, key func(T) O) {
+	if len(s) < 2 {
+		return
+	}
+	type keyed struct {
+		k O
+		t T
+	}
+	ks := make([]keyed, len(s))
+	for i, t := range s {
+		ks[i] = keyed{k: key(t), t: t}
+	}
+	slices.SortStableFunc(ks, func(a, b keyed) int { return cmp.Compare(a.k, b.k) })
+	for i := range ks {
+		s[i] = ks[i].t
+	}
+}
+
+// Partition splits s into elements matched pred and the others, both keep the order in s
+func Partition[S ~[]T, T any](s S, pred func(T) bool) (matched, unmatched S) {
+	for _, t := range s {
+		if pred(t) {
+			matched = append(matched, t)
+		} else {
+			unmatched = append(unmatched, t)
+		}
+	}
+	return matched, unmatched
+}
+
+// Pages splits s into pages with pageSize elements (the last one may be less), pageSize <= 0 means
+// 100 like BatchCall. Pages share the underlying array with s.
+func Pages[S ~[]T, T any](s S, pageSize int) []S {
+	if len(s) == 0 {
+		return nil
+	}
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	pages := make([]S, 0, (len(s)+pageSize-1)/pageSize)
+	for start := 0; start < len(s); start += pageSize {
+		end := min(start+pageSize, len(s))
+		pages = append(pages, s[start:end:end])
+	}
+	return pages
+}
+
+// BinarySearch searches k in ks sorted in ascending order, returns the position where k is found or
+// would be inserted.
+func BinarySearch[K cmp.Ordered](ks KS[K], k K) (int, bool) {
+	return slices.BinarySearch(ks, k)
+}
+
+// TopK returns the k greatest elements by compare in descending order, s is not changed
+func TopK[S ~[]T, T any](s S, k int, compare func(a, b T) int) S {
+	if k <= 0 || len(s) == 0 {
+		return nil
+	}
+	if k >= len(s) {
+		ret := slices.Clone(s)
+		slices.SortStableFunc(ret, func(a, b T) int { return compare(b, a) })
+		return ret
+	}
+	// min-heap of the k greatest elements seen
+	h := make(S, 0, k)
+	down := func(i int) {
+		for {
+			l, smallest := 2*i+1, i
+			if l < len(h) && compare(h[l], h[smallest]) < 0 {
+				smallest = l
+			}
+			if r := l + 1; r < len(h) && compare(h[r], h[smallest]) < 0 {
+				smallest = r
+			}
+			if smallest == i {
+				return
+			}
+			h[i], h[smallest] = h[smallest], h[i]
+			i = smallest
+		}
+	}
+	for _, t := range s {
+		if len(h) < k {
+			h = append(h, t)
+			for i := len(h) - 1; i > 0; {
+				p := (i - 1) / 2
+				if compare(h[i], h[p]) >= 0 {
+					break
+				}
+				h[i], h[p] = h[p], h[i]
+				i = p
+			}
+		} else if compare(t, h[0]) > 0 {
+			h[0] = t
+			down(0)
+		}
+	}
+	slices.SortFunc(h, func(a, b T) int { return compare(b, a) })
+	return h
+}
+
+// Shuffle shuffles s in place by the random source, the result is reproducible with the same source
+func Shuffle[S ~[]T, T any](s S, src rand.Source) {
+	rand.New(src).Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+}
+
+// Shuffle returns a shuffled copy of ks, the same seed generates the same order
+func (ks KS[K]) Shuffle(seed uint64) KS[K] {
+	ret := ks.Clone()
+	Shuffle(ret, rand.NewPCG(seed, seed))
+	return ret
+}
+
+// BinarySearchFunc searches target in ks sorted by compare
+func (ks KS[K]) BinarySearchFunc(target K, compare func(a, b K) int) (int, bool) {
+	return slices.BinarySearchFunc(ks, target, compare)
+}
+
+// Diff compares ks with newer as sets: added are in newer only (in the order of newer), removed are
+// in ks only and kept are in both (in the order of ks). Duplicates are removed.
+func (ks KS[K]) Diff(newer KS[K]) (added, removed, kept KS[K]) {
+	olds, news := ks.Map(), newer.Map()
+	for _, k := range ks.Dedup() {
+		if news.IsExist(k) {
+			kept = append(kept, k)
+		} else {
+			removed = append(removed, k)
+		}
+	}
+	for _, k := range newer.Dedup() {
+		if !olds.IsExist(k) {
+			added = append(added, k)
+		}
+	}
+	return added, removed, kept
+}
+
+// Intersect returns the deduplicated elements in both ks and o, in the order of ks
+func (ks KS[K]) Intersect(o KS[K]) KS[K] {
+	return ks.Dedup().In(o.Map())
+}
+
+// Union returns the deduplicated elements in ks or o, elements of ks come first
+func (ks KS[K]) Union(o KS[K]) KS[K] {
+	ret := make(KS[K], 0, len(ks)+len(o))
+	ret = append(ret, ks...)
+	return append(ret, o...).Dedup()
+}
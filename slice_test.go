@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSliceAlgorithms(t *testing.T) {
+	words := KS[string]{"pear", "fig", "banana", "kiwi", "apple", "date"}
+	sorted := words.Clone()
+	SortBy(sorted, func(s string) int { return len(s) })
+	if !sorted.Equal(KS[string]{"fig", "pear", "kiwi", "date", "apple", "banana"}) {
+		t.Fatalf("sort by: %v", sorted)
+	}
+
+	short, long := Partition(words, func(s string) bool { return len(s) <= 4 })
+	if !short.Equal(KS[string]{"pear", "fig", "kiwi", "date"}) || !long.Equal(KS[string]{"banana", "apple"}) {
+		t.Fatalf("partition: %v %v", short, long)
+	}
+
+	pages := Pages(words, 4)
+	if len(pages) != 2 || !pages[1].Equal(KS[string]{"apple", "date"}) || cap(pages[0]) != 4 {
+		t.Fatalf("pages: %v", pages)
+	}
+	if Pages(KS[int](nil), 3) != nil || len(Pages(make([]int, 250), 0)) != 3 {
+		t.Fatal("pages of empty or default size")
+	}
+
+	nums := KS[int]{1, 3, 5, 7}
+	if i, ok := BinarySearch(nums, 5); !ok || i != 2 {
+		t.Fatalf("binary search: %d", i)
+	}
+	if i, ok := nums.BinarySearchFunc(4, cmp.Compare[int]); ok || i != 2 {
+		t.Fatalf("binary search func: %d", i)
+	}
+
+	top := TopK([]int{5, 1, 9, 3, 9, 7, 2}, 3, cmp.Compare[int])
+	if !slices.Equal(top, []int{9, 9, 7}) {
+		t.Fatalf("top k: %v", top)
+	}
+	if got := TopK(words, 10, func(a, b string) int { return strings.Compare(a, b) }); len(got) != 6 || got[0] != "pear" {
+		t.Fatalf("top k all: %v", got)
+	}
+	if TopK(words, 0, strings.Compare) != nil {
+		t.Fatal("top 0")
+	}
+
+	s1, s2 := words.Shuffle(42), words.Shuffle(42)
+	if !s1.Equal(s2) {
+		t.Fatalf("shuffle: %v %v", s1, s2)
+	}
+	if !slices.Equal(slices.Sorted(slices.Values(s1)), slices.Sorted(slices.Values(words))) {
+		t.Fatal("shuffle should keep elements")
+	}
+	ints := []int{1, 2, 3, 4, 5}
+	Shuffle(ints, rand.NewPCG(1, 2))
+	again := []int{1, 2, 3, 4, 5}
+	Shuffle(again, rand.NewPCG(1, 2))
+	if !slices.Equal(ints, again) {
+		t.Fatal("shuffle with the same source")
+	}
+
+	added, removed, kept := KS[int]{1, 2, 3, 2}.Diff(KS[int]{4, 3, 1, 5, 4})
+	if !added.Equal(KS[int]{4, 5}) || !removed.Equal(KS[int]{2}) || !kept.Equal(KS[int]{1, 3}) {
+		t.Fatalf("diff: %v %v %v", added, removed, kept)
+	}
+	if got := (KS[int]{3, 1, 2, 3}).Intersect(KS[int]{2, 3, 9}); !got.Equal(KS[int]{3, 2}) {
+		t.Fatalf("intersect: %v", got)
+	}
+	if got := (KS[int]{3, 1, 3}).Union(KS[int]{2, 1, 4}); !got.Equal(KS[int]{3, 1, 2, 4}) {
+		t.Fatalf("union: %v", got)
+	}
+}
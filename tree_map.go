@@ -0,0 +1,528 @@
+package tools
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"slices"
+)
+
+type treeNode[K any, V any] struct {
+	k           K
+	v           V
+	prio        uint64
+	size        int
+	left, right *treeNode[K, V]
+}
+
+func (n *treeNode[K, V]) getSize() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *treeNode[K, V]) update() {
+	n.size = n.left.getSize() + n.right.getSize() + 1
+}
+
+// TreeMap 按key有序的map，由treap实现，增删查、Floor/Ceiling、Rank/Select、Split的期望时间复杂度都是O(log n)。
+// 线程不安全。
+type TreeMap[K any, V any] struct {
+	cmp  func(a, b K) int
+	root *treeNode[K, V]
+}
+
+func NewTreeMap[K cmp.Ordered, V any]() *TreeMap[K, V] {
+	return NewTreeMapFunc[K, V](cmp.Compare[K])
+}
+
+// NewTreeMapFunc creates a TreeMap ordered by compare, which returns a negative number when a < b,
+// a positive number when a > b and zero when a == b.
+func NewTreeMapFunc[K any, V any](compare func(a, b K) int) *TreeMap[K, V] {
+	return &TreeMap[K, V]{cmp: compare}
+}
+
+// merge concatenates a and b, all keys in a must be less than keys in b
+func (m *TreeMap[K, V]) merge(a, b *treeNode[K, V]) *treeNode[K, V] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.prio > b.prio {
+		a.right = m.merge(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = m.merge(a, b.left)
+	b.update()
+	return b
+}
+
+// split splits n into keys less than k, the node of k (if exists) and keys greater than k
+func (m *TreeMap[K, V]) split(n *treeNode[K, V], k K) (l, eq, r *treeNode[K, V]) {
+	if n == nil {
+		return nil, nil, nil
+	}
+	switch c := m.cmp(k, n.k); {
+	case c < 0:
+		l, eq, n.left = m.split(n.left, k)
+		n.update()
+		return l, eq, n
+	case c > 0:
+		n.right, eq, r = m.split(n.right, k)
+		n.update()
+		return n, eq, r
+	default:
+		l, r = n.left, n.right
+		n.left, n.right = nil, nil
+		n.update()
+		return l, n, r
+	}
+}
+
+// union merges a and b, values in b take precedence
+func (m *TreeMap[K, V]) union(a, b *treeNode[K, V]) *treeNode[K, V] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.prio > b.prio {
+		bl, eq, br := m.split(b, a.k)
+		if eq != nil {
+			a.v = eq.v
+		}
+		a.left = m.union(a.left, bl)
+		a.right = m.union(a.right, br)
+		a.update()
+		return a
+	}
+	al, _, ar := m.split(a, b.k)
+	b.left = m.union(al, b.left)
+	b.right = m.union(ar, b.right)
+	b.update()
+	return b
+}
+
+func (m *TreeMap[K, V]) find(k K) *treeNode[K, V] {
+	n := m.root
+	for n != nil {
+		c := m.cmp(k, n.k)
+		if c == 0 {
+			return n
+		}
+		if c < 0 {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return nil
+}
+
+// Put sets the value of key, replaces the value if key exists
+func (m *TreeMap[K, V]) Put(key K, value V) *TreeMap[K, V] {
+	if n := m.find(key); n != nil {
+		n.v = value
+		return m
+	}
+	l, _, r := m.split(m.root, key)
+	n := &treeNode[K, V]{k: key, v: value, prio: rand.Uint64(), size: 1}
+	m.root = m.merge(m.merge(l, n), r)
+	return m
+}
+
+func (m *TreeMap[K, V]) Puts(it iter.Seq2[K, V]) *TreeMap[K, V] {
+	for k, v := range it {
+		m.Put(k, v)
+	}
+	return m
+}
+
+func (m *TreeMap[K, V]) Get(k K) (v V, exist bool) {
+	if n := m.find(k); n != nil {
+		return n.v, true
+	}
+	return v, false
+}
+
+func (m *TreeMap[K, V]) IsExist(k K) bool {
+	return m.find(k) != nil
+}
+
+func (m *TreeMap[K, V]) Delete(ks ...K) {
+	for _, k := range ks {
+		if m.find(k) == nil {
+			continue
+		}
+		l, _, r := m.split(m.root, k)
+		m.root = m.merge(l, r)
+	}
+}
+
+func (m *TreeMap[K, V]) Len() int { return m.root.getSize() }
+
+func (m *TreeMap[K, V]) Clear() { m.root = nil }
+
+func (m *TreeMap[K, V]) Min() (k K, v V, ok bool) {
+	n := m.root
+	if n == nil {
+		return k, v, false
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.k, n.v, true
+}
+
+func (m *TreeMap[K, V]) Max() (k K, v V, ok bool) {
+	n := m.root
+	if n == nil {
+		return k, v, false
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.k, n.v, true
+}
+
+// floor returns the node with the greatest key less than (or equal to if inclusive) k
+func (m *TreeMap[K, V]) floor(k K, inclusive bool) *treeNode[K, V] {
+	var ret *treeNode[K, V]
+	for n := m.root; n != nil; {
+		if c := m.cmp(n.k, k); c < 0 || (inclusive && c == 0) {
+			ret, n = n, n.right
+		} else {
+			n = n.left
+		}
+	}
+	return ret
+}
+
+// ceiling returns the node with the least key greater than (or equal to if inclusive) k
+func (m *TreeMap[K, V]) ceiling(k K, inclusive bool) *treeNode[K, V] {
+	var ret *treeNode[K, V]
+	for n := m.root; n != nil; {
+		if c := m.cmp(n.k, k); c > 0 || (inclusive && c == 0) {
+			ret, n = n, n.left
+		} else {
+			n = n.right
+		}
+	}
+	return ret
+}
+
+func nodeResult[K any, V any](n *treeNode[K, V]) (k K, v V, ok bool) {
+	if n == nil {
+		return k, v, false
+	}
+	return n.k, n.v, true
+}
+
+// Floor returns the greatest key less than or equal to k
+func (m *TreeMap[K, V]) Floor(k K) (K, V, bool) { return nodeResult(m.floor(k, true)) }
+
+// Ceiling returns the least key greater than or equal to k
+func (m *TreeMap[K, V]) Ceiling(k K) (K, V, bool) { return nodeResult(m.ceiling(k, true)) }
+
+// Lower returns the greatest key strictly less than k
+func (m *TreeMap[K, V]) Lower(k K) (K, V, bool) { return nodeResult(m.floor(k, false)) }
+
+// Higher returns the least key strictly greater than k
+func (m *TreeMap[K, V]) Higher(k K) (K, V, bool) { return nodeResult(m.ceiling(k, false)) }
+
+// Rank returns the number of keys less than k
+func (m *TreeMap[K, V]) Rank(k K) int {
+	rank := 0
+	for n := m.root; n != nil; {
+		if m.cmp(k, n.k) <= 0 {
+			n = n.left
+		} else {
+			rank += n.left.getSize() + 1
+			n = n.right
+		}
+	}
+	return rank
+}
+
+// Select returns the i-th (starts from 0) least key
+func (m *TreeMap[K, V]) Select(i int) (k K, v V, ok bool) {
+	if i < 0 || i >= m.Len() {
+		return k, v, false
+	}
+	n := m.root
+	for {
+		ls := n.left.getSize()
+		switch {
+		case i < ls:
+			n = n.left
+		case i > ls:
+			i -= ls + 1
+			n = n.right
+		default:
+			return n.k, n.v, true
+		}
+	}
+}
+
+// treeBound a range boundary, nil for unbounded
+type treeBound[K any] struct {
+	k         *K
+	inclusive bool
+}
+
+func (m *TreeMap[K, V]) aboveLow(k K, lo treeBound[K]) bool {
+	if lo.k == nil {
+		return true
+	}
+	c := m.cmp(k, *lo.k)
+	return c > 0 || (lo.inclusive && c == 0)
+}
+
+func (m *TreeMap[K, V]) belowHigh(k K, hi treeBound[K]) bool {
+	if hi.k == nil {
+		return true
+	}
+	c := m.cmp(k, *hi.k)
+	return c < 0 || (hi.inclusive && c == 0)
+}
+
+func (m *TreeMap[K, V]) ascend(n *treeNode[K, V], lo, hi treeBound[K], yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	above, below := m.aboveLow(n.k, lo), m.belowHigh(n.k, hi)
+	if above && !m.ascend(n.left, lo, hi, yield) {
+		return false
+	}
+	if above && below && !yield(n.k, n.v) {
+		return false
+	}
+	if below {
+		return m.ascend(n.right, lo, hi, yield)
+	}
+	return true
+}
+
+func (m *TreeMap[K, V]) descend(n *treeNode[K, V], lo, hi treeBound[K], yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	above, below := m.aboveLow(n.k, lo), m.belowHigh(n.k, hi)
+	if below && !m.descend(n.right, lo, hi, yield) {
+		return false
+	}
+	if above && below && !yield(n.k, n.v) {
+		return false
+	}
+	if above {
+		return m.descend(n.left, lo, hi, yield)
+	}
+	return true
+}
+
+// All iterates in ascending order of keys
+func (m *TreeMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.ascend(m.root, treeBound[K]{}, treeBound[K]{}, yield)
+	}
+}
+
+// Backward iterates in descending order of keys
+func (m *TreeMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.descend(m.root, treeBound[K]{}, treeBound[K]{}, yield)
+	}
+}
+
+// Range iterates keys in [from, to) in ascending order
+func (m *TreeMap[K, V]) Range(from, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.ascend(m.root, treeBound[K]{&from, true}, treeBound[K]{&to, false}, yield)
+	}
+}
+
+// Ascend iterates keys greater than or equal to from in ascending order
+func (m *TreeMap[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.ascend(m.root, treeBound[K]{&from, true}, treeBound[K]{}, yield)
+	}
+}
+
+// Descend iterates keys less than or equal to from in descending order
+func (m *TreeMap[K, V]) Descend(from K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.descend(m.root, treeBound[K]{}, treeBound[K]{&from, true}, yield)
+	}
+}
+
+func (m *TreeMap[K, V]) KeySeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func (m *TreeMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (m *TreeMap[K, V]) Keys() []K   { return slices.Collect(m.KeySeq()) }
+func (m *TreeMap[K, V]) Values() []V { return slices.Collect(m.ValuesSeq()) }
+
+func (m *TreeMap[K, V]) cloneNode(n *treeNode[K, V]) *treeNode[K, V] {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	c.left, c.right = m.cloneNode(n.left), m.cloneNode(n.right)
+	return &c
+}
+
+func (m *TreeMap[K, V]) Clone() *TreeMap[K, V] {
+	return &TreeMap[K, V]{cmp: m.cmp, root: m.cloneNode(m.root)}
+}
+
+// Split moves keys greater than or equal to k into a new TreeMap and returns it
+func (m *TreeMap[K, V]) Split(k K) *TreeMap[K, V] {
+	l, eq, r := m.split(m.root, k)
+	m.root = l
+	return &TreeMap[K, V]{cmp: m.cmp, root: m.merge(eq, r)}
+}
+
+// Merge moves all keys of o into m, values in o take precedence, and o becomes empty. m and o must
+// have the same order.
+func (m *TreeMap[K, V]) Merge(o *TreeMap[K, V]) *TreeMap[K, V] {
+	if o == nil || o == m {
+		return m
+	}
+	m.root = m.union(m.root, o.root)
+	o.root = nil
+	return m
+}
+
+func (m *TreeMap[K, V]) selfCheck() error {
+	var check func(n *treeNode[K, V], lo, hi *K) error
+	check = func(n *treeNode[K, V], lo, hi *K) error {
+		if n == nil {
+			return nil
+		}
+		if (lo != nil && m.cmp(n.k, *lo) <= 0) || (hi != nil && m.cmp(n.k, *hi) >= 0) {
+			return fmt.Errorf("order broken at k:%v", n.k)
+		}
+		if n.size != n.left.getSize()+n.right.getSize()+1 {
+			return fmt.Errorf("size mismatch at k:%v", n.k)
+		}
+		if (n.left != nil && n.left.prio > n.prio) || (n.right != nil && n.right.prio > n.prio) {
+			return fmt.Errorf("heap broken at k:%v", n.k)
+		}
+		if err := check(n.left, lo, &n.k); err != nil {
+			return err
+		}
+		return check(n.right, &n.k, hi)
+	}
+	return check(m.root, nil, nil)
+}
+
+// TreeSet 有序集合，线程不安全。
+type TreeSet[K any] struct {
+	m *TreeMap[K, struct{}]
+}
+
+func NewTreeSet[K cmp.Ordered](ks ...K) *TreeSet[K] {
+	return NewTreeSetFunc(cmp.Compare[K], ks...)
+}
+
+func NewTreeSetFunc[K any](compare func(a, b K) int, ks ...K) *TreeSet[K] {
+	s := &TreeSet[K]{m: NewTreeMapFunc[K, struct{}](compare)}
+	return s.Add(ks...)
+}
+
+func setResult[K any](k K, _ struct{}, ok bool) (K, bool) {
+	return k, ok
+}
+
+func (s *TreeSet[K]) Add(ks ...K) *TreeSet[K] {
+	for _, k := range ks {
+		s.m.Put(k, struct{}{})
+	}
+	return s
+}
+
+func (s *TreeSet[K]) Adds(it iter.Seq[K]) *TreeSet[K] {
+	for k := range it {
+		s.m.Put(k, struct{}{})
+	}
+	return s
+}
+
+func (s *TreeSet[K]) Delete(ks ...K) *TreeSet[K] {
+	s.m.Delete(ks...)
+	return s
+}
+
+func (s *TreeSet[K]) IsExist(k K) bool    { return s.m.IsExist(k) }
+func (s *TreeSet[K]) Len() int            { return s.m.Len() }
+func (s *TreeSet[K]) Clear()              { s.m.Clear() }
+func (s *TreeSet[K]) Min() (K, bool)      { return setResult(s.m.Min()) }
+func (s *TreeSet[K]) Max() (K, bool)      { return setResult(s.m.Max()) }
+func (s *TreeSet[K]) Floor(k K) (K, bool) { return setResult(s.m.Floor(k)) }
+
+func (s *TreeSet[K]) Ceiling(k K) (K, bool) { return setResult(s.m.Ceiling(k)) }
+func (s *TreeSet[K]) Lower(k K) (K, bool)   { return setResult(s.m.Lower(k)) }
+func (s *TreeSet[K]) Higher(k K) (K, bool)  { return setResult(s.m.Higher(k)) }
+func (s *TreeSet[K]) Rank(k K) int          { return s.m.Rank(k) }
+func (s *TreeSet[K]) Select(i int) (K, bool) {
+	return setResult(s.m.Select(i))
+}
+
+func keysOf[K any](it iter.Seq2[K, struct{}]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range it {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// All iterates in ascending order
+func (s *TreeSet[K]) All() iter.Seq[K] { return keysOf(s.m.All()) }
+
+// Backward iterates in descending order
+func (s *TreeSet[K]) Backward() iter.Seq[K] { return keysOf(s.m.Backward()) }
+
+// Range iterates keys in [from, to) in ascending order
+func (s *TreeSet[K]) Range(from, to K) iter.Seq[K] { return keysOf(s.m.Range(from, to)) }
+
+func (s *TreeSet[K]) Ascend(from K) iter.Seq[K]  { return keysOf(s.m.Ascend(from)) }
+func (s *TreeSet[K]) Descend(from K) iter.Seq[K] { return keysOf(s.m.Descend(from)) }
+
+func (s *TreeSet[K]) Slice() []K { return s.m.Keys() }
+
+func (s *TreeSet[K]) Clone() *TreeSet[K] { return &TreeSet[K]{m: s.m.Clone()} }
+
+// Split moves keys greater than or equal to k into a new TreeSet and returns it
+func (s *TreeSet[K]) Split(k K) *TreeSet[K] { return &TreeSet[K]{m: s.m.Split(k)} }
+
+// Merge moves all keys of o into s, and o becomes empty
+func (s *TreeSet[K]) Merge(o *TreeSet[K]) *TreeSet[K] {
+	if o != nil {
+		s.m.Merge(o.m)
+	}
+	return s
+}
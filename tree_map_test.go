@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTreeMap(t *testing.T) {
+	m := NewTreeMap[int, int]()
+	model := make(map[int]int)
+	for i := 0; i < 2000; i++ {
+		k := rand.Intn(500)
+		if rand.Intn(4) == 0 {
+			m.Delete(k)
+			delete(model, k)
+		} else {
+			m.Put(k, i)
+			model[k] = i
+		}
+	}
+	if err := m.selfCheck(); err != nil {
+		t.Fatal(err)
+	}
+	keys := slices.Sorted(maps.Keys(model))
+	if m.Len() != len(model) || !slices.Equal(m.Keys(), keys) {
+		t.Fatalf("keys mismatch: %d %d", m.Len(), len(model))
+	}
+	for k, v := range m.All() {
+		if model[k] != v {
+			t.Fatalf("value of %d: %d != %d", k, v, model[k])
+		}
+	}
+	var backward []int
+	for _, v := range m.Backward() {
+		backward = append(backward, v)
+	}
+	slices.Reverse(backward)
+	if !slices.Equal(backward, m.Values()) {
+		t.Fatal("backward mismatch")
+	}
+	for i, k := range keys {
+		if m.Rank(k) != i {
+			t.Fatalf("rank of %d: %d", k, m.Rank(k))
+		}
+		if sk, _, ok := m.Select(i); !ok || sk != k {
+			t.Fatalf("select %d: %d", i, sk)
+		}
+	}
+	if _, _, ok := m.Select(len(keys)); ok {
+		t.Fatal("select out of range")
+	}
+	for q := -1; q <= 501; q++ {
+		i, found := slices.BinarySearch(keys, q)
+		check := func(name string, k int, ok bool, idx int) {
+			wantOk := idx >= 0 && idx < len(keys)
+			if ok != wantOk || (ok && k != keys[idx]) {
+				t.Fatalf("%s(%d) = %d %t, want index %d", name, q, k, ok, idx)
+			}
+		}
+		k, _, ok := m.Ceiling(q)
+		check("Ceiling", k, ok, i)
+		k, _, ok = m.Lower(q)
+		check("Lower", k, ok, i-1)
+		k, _, ok = m.Higher(q)
+		if found {
+			check("Higher", k, ok, i+1)
+		} else {
+			check("Higher", k, ok, i)
+		}
+		k, _, ok = m.Floor(q)
+		if found {
+			check("Floor", k, ok, i)
+		} else {
+			check("Floor", k, ok, i-1)
+		}
+	}
+
+	var ranged []int
+	for k := range m.Range(100, 200) {
+		ranged = append(ranged, k)
+	}
+	lo, _ := slices.BinarySearch(keys, 100)
+	hi, _ := slices.BinarySearch(keys, 200)
+	if !slices.Equal(ranged, keys[lo:hi]) {
+		t.Fatalf("range: %v", ranged)
+	}
+	var desc []int
+	for k := range m.Descend(keys[5]) {
+		desc = append(desc, k)
+	}
+	if !slices.Equal(desc, []int{keys[5], keys[4], keys[3], keys[2], keys[1], keys[0]}) {
+		t.Fatalf("descend: %v", desc)
+	}
+	for k := range m.Ascend(keys[3]) {
+		if k != keys[3] {
+			t.Fatalf("ascend: %d", k)
+		}
+		break
+	}
+
+	c := m.Clone()
+	right := c.Split(250)
+	if err := right.selfCheck(); err != nil {
+		t.Fatal(err)
+	}
+	if mk, _, _ := c.Max(); mk >= 250 || c.Len()+right.Len() != m.Len() {
+		t.Fatalf("split: %d %d", c.Len(), right.Len())
+	}
+	if mk, _, _ := right.Min(); mk < 250 {
+		t.Fatalf("split min: %d", mk)
+	}
+	c.Merge(right)
+	if err := c.selfCheck(); err != nil || right.Len() != 0 || !slices.Equal(c.Keys(), keys) {
+		t.Fatalf("merge back: %v", err)
+	}
+
+	o := NewTreeMap[int, int]()
+	o.Put(keys[0], -1).Put(1000, -2)
+	c.Merge(o)
+	if v, _ := c.Get(keys[0]); v != -1 || !c.IsExist(1000) || c.Len() != m.Len()+1 {
+		t.Fatal("merge should overwrite by o")
+	}
+	if err := c.selfCheck(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := m.Get(keys[0]); v != model[keys[0]] {
+		t.Fatal("clone should be independent")
+	}
+}
+
+func TestTreeSet(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewTreeSetFunc(func(a, b time.Time) int { return a.Compare(b) })
+	for _, h := range []int{5, 1, 3, 9, 7, 3} {
+		s.Add(base.Add(time.Duration(h) * time.Hour))
+	}
+	hours := func(ts []time.Time) []int {
+		var ret []int
+		for _, tm := range ts {
+			ret = append(ret, int(tm.Sub(base)/time.Hour))
+		}
+		return ret
+	}
+	if got := hours(s.Slice()); !slices.Equal(got, []int{1, 3, 5, 7, 9}) {
+		t.Fatalf("slice: %v", got)
+	}
+	if got := hours(slices.Collect(s.Range(base.Add(2*time.Hour), base.Add(7*time.Hour)))); !slices.Equal(got, []int{3, 5}) {
+		t.Fatalf("range: %v", got)
+	}
+	if f, ok := s.Floor(base.Add(4 * time.Hour)); !ok || !f.Equal(base.Add(3*time.Hour)) {
+		t.Fatalf("floor: %v", f)
+	}
+	if _, ok := s.Higher(base.Add(9 * time.Hour)); ok {
+		t.Fatal("higher of max")
+	}
+
+	is := NewTreeSet(4, 2, 8, 6)
+	right := is.Split(5)
+	if !slices.Equal(is.Slice(), []int{2, 4}) || !slices.Equal(right.Slice(), []int{6, 8}) {
+		t.Fatalf("split: %v %v", is.Slice(), right.Slice())
+	}
+	is.Merge(right.Add(1)).Delete(4)
+	if !slices.Equal(is.Slice(), []int{1, 2, 6, 8}) || is.Rank(6) != 2 || right.Len() != 0 {
+		t.Fatalf("merge: %v", is.Slice())
+	}
+	if k, ok := is.Select(3); !ok || k != 8 {
+		t.Fatalf("select: %d", k)
+	}
+	if got := slices.Collect(is.Backward()); !slices.Equal(got, []int{8, 6, 2, 1}) {
+		t.Fatalf("backward: %v", got)
+	}
+}
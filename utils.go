@@ -4,48 +4,156 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"hash"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/stephenfire/go-tools/internal/orderedjson"
+	"github.com/stephenfire/go-tools/log"
 )
 
-func JsonString(m any) (string, error) {
+// ErrJsonTooLarge is returned when the marshalled json exceeds JsonOptions.MaxSize
+var ErrJsonTooLarge = errors.New("tools: json too large")
+
+// maxSafeJsonInt 2^53-1, the max integer can be represented exactly by a javascript number
+const maxSafeJsonInt = 1<<53 - 1
+
+// JsonOptions options of JsonString and JsonPrettyString, the zero value keeps the behavior of
+// json.Marshal
+type JsonOptions struct {
+	// NoHTMLEscape disables escaping of <, > and & in strings
+	NoHTMLEscape bool
+	// SortKeys sorts the members of all objects including structs, keys of maps are always sorted
+	SortKeys bool
+	// Prefix and Indent are used to indent the output like json.Indent. JsonPrettyString indents
+	// with two spaces when Indent is empty.
+	Prefix string
+	Indent string
+	// OmitNull removes members with null values from all objects
+	OmitNull bool
+	// Int64AsString writes integers out of ±(2^53-1) as strings, so that javascript clients will not
+	// lose precision
+	Int64AsString bool
+	// MaxSize returns ErrJsonTooLarge if the output is larger than MaxSize bytes, 0 means no limit.
+	// It is checked after marshalling, which limits the output but not the memory used.
+	MaxSize int
+	// Redactor masks sensitive fields and values before marshalling if not nil, see log.Redactor
+	Redactor *log.Redactor
+}
+
+func (o JsonOptions) rewrite() bool {
+	return o.SortKeys || o.OmitNull || o.Int64AsString
+}
+
+// rewriteValue applies SortKeys, OmitNull and Int64AsString to a value decoded by orderedjson.Decode,
+// objects keep the order of struct fields when SortKeys is false
+func (o JsonOptions) rewriteValue(v any) any {
+	switch c := v.(type) {
+	case orderedjson.Members:
+		ms := c[:0]
+		for _, m := range c {
+			if m.Value == nil && o.OmitNull {
+				continue
+			}
+			ms = append(ms, orderedjson.Member{Key: m.Key, Value: o.rewriteValue(m.Value)})
+		}
+		if o.SortKeys {
+			slices.SortStableFunc(ms, func(a, b orderedjson.Member) int { return strings.Compare(a.Key, b.Key) })
+		}
+		return ms
+	case []any:
+		for i, vv := range c {
+			c[i] = o.rewriteValue(vv)
+		}
+	case json.Number:
+		if o.Int64AsString && !strings.ContainsAny(string(c), ".eE") {
+			if i, ok := new(big.Int).SetString(string(c), 10); ok && i.CmpAbs(big.NewInt(maxSafeJsonInt)) > 0 {
+				return string(c)
+			}
+		}
+	}
+	return v
+}
+
+func marshalJson(m any, opts JsonOptions, pretty bool) (string, error) {
 	if m == nil {
 		return "", nil
 	}
-	bs, err := json.Marshal(m)
-	if err != nil {
-		return "", err
+	var v any = m
+	if opts.Redactor != nil {
+		v = opts.Redactor.Redact(m)
+	}
+	if opts.rewrite() {
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		dec := json.NewDecoder(bytes.NewReader(bs))
+		dec.UseNumber()
+		if v, err = orderedjson.Decode(dec); err != nil {
+			return "", err
+		}
+		v = opts.rewriteValue(v)
 	}
 	buf := new(bytes.Buffer)
-	if err = json.Compact(buf, bs); err != nil {
-		return string(bs), nil
-	} else {
-		return buf.String(), nil
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(!opts.NoHTMLEscape)
+	indent := opts.Indent
+	if pretty && indent == "" {
+		indent = "  "
+	}
+	if indent != "" || opts.Prefix != "" {
+		enc.SetIndent(opts.Prefix, indent)
 	}
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	bs := bytes.TrimRight(buf.Bytes(), "\n")
+	if opts.MaxSize > 0 && len(bs) > opts.MaxSize {
+		return "", fmt.Errorf("%w: %d bytes exceeds the limit of %d", ErrJsonTooLarge, len(bs), opts.MaxSize)
+	}
+	return string(bs), nil
+}
+
+func JsonString(m any, opts ...JsonOptions) (string, error) {
+	return marshalJson(m, VariadicParam(opts), false)
 }
 
-func MustJsonString(m any) string {
-	a, _ := JsonString(m)
+func MustJsonString(m any, opts ...JsonOptions) string {
+	a, _ := JsonString(m, opts...)
 	return a
 }
 
-func JsonPrettyString(m any) (string, error) {
-	if m == nil {
-		return "", nil
-	}
-	bs, err := json.Marshal(m)
+// LogJsonString like MustJsonString, but logs the error and returns the %+v format of m if failed
+func LogJsonString(m any, opts ...JsonOptions) string {
+	a, err := JsonString(m, opts...)
 	if err != nil {
-		return "", err
-	}
-	buf := new(bytes.Buffer)
-	if err = json.Indent(buf, bs, "", "  "); err != nil {
-		return string(bs), nil
-	} else {
-		return buf.String(), nil
+		log.Warnf("tools: json marshal %T failed: %v", m, err)
+		return fmt.Sprintf("%+v", m)
 	}
+	return a
+}
+
+func JsonPrettyString(m any, opts ...JsonOptions) (string, error) {
+	return marshalJson(m, VariadicParam(opts), true)
 }
 
-func MustJsonPrettyString(m any) string {
-	a, _ := JsonPrettyString(m)
+func MustJsonPrettyString(m any, opts ...JsonOptions) string {
+	a, _ := JsonPrettyString(m, opts...)
+	return a
+}
+
+// LogJsonPrettyString like MustJsonPrettyString, but logs the error and returns the %+v format of m
+// if failed
+func LogJsonPrettyString(m any, opts ...JsonOptions) string {
+	a, err := JsonPrettyString(m, opts...)
+	if err != nil {
+		log.Warnf("tools: json marshal %T failed: %v", m, err)
+		return fmt.Sprintf("%+v", m)
+	}
 	return a
 }
 
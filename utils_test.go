@@ -1,7 +1,11 @@
 package tools
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/stephenfire/go-tools/log"
 )
 
 func TestJsonString(t *testing.T) {
@@ -65,3 +69,58 @@ func TestJsonString(t *testing.T) {
 		})
 	}
 }
+
+func TestJsonOptions(t *testing.T) {
+	type inner struct {
+		Z string `json:"z"`
+		A *int   `json:"a"`
+	}
+	type outer struct {
+		Name  string `json:"name"`
+		ID    int64  `json:"id"`
+		Small int64  `json:"small"`
+		Inner inner  `json:"inner"`
+		List  []any  `json:"list"`
+	}
+	v := outer{Name: "<b>&", ID: 1 << 60, Small: 7, Inner: inner{Z: "z"}, List: []any{nil, int64(-1 << 62)}}
+	tests := []struct {
+		name    string
+		opts    JsonOptions
+		pretty  bool
+		want    string
+		wantErr error
+	}{
+		{"default", JsonOptions{}, false, `{"name":"\u003cb\u003e\u0026","id":1152921504606846976,"small":7,"inner":{"z":"z","a":null},"list":[null,-4611686018427387904]}`, nil},
+		{"no html escape", JsonOptions{NoHTMLEscape: true}, false, `{"name":"<b>&","id":1152921504606846976,"small":7,"inner":{"z":"z","a":null},"list":[null,-4611686018427387904]}`, nil},
+		{"sort keys", JsonOptions{SortKeys: true}, false, `{"id":1152921504606846976,"inner":{"a":null,"z":"z"},"list":[null,-4611686018427387904],"name":"\u003cb\u003e\u0026","small":7}`, nil},
+		{"omit null", JsonOptions{OmitNull: true, NoHTMLEscape: true}, false, `{"name":"<b>&","id":1152921504606846976,"small":7,"inner":{"z":"z"},"list":[null,-4611686018427387904]}`, nil},
+		{"omit null sort keys", JsonOptions{OmitNull: true, SortKeys: true}, false, `{"id":1152921504606846976,"inner":{"z":"z"},"list":[null,-4611686018427387904],"name":"\u003cb\u003e\u0026","small":7}`, nil},
+		{"int64 as string", JsonOptions{Int64AsString: true, NoHTMLEscape: true}, false, `{"name":"<b>&","id":"1152921504606846976","small":7,"inner":{"z":"z","a":null},"list":[null,"-4611686018427387904"]}`, nil},
+		{"indent", JsonOptions{Indent: "\t", OmitNull: true, SortKeys: true}, false, "{\n\t\"id\": 1152921504606846976,\n\t\"inner\": {\n\t\t\"z\": \"z\"\n\t},\n\t\"list\": [\n\t\tnull,\n\t\t-4611686018427387904\n\t],\n\t\"name\": \"\\u003cb\\u003e\\u0026\",\n\t\"small\": 7\n}", nil},
+		{"pretty", JsonOptions{NoHTMLEscape: true}, true, "{\n  \"name\": \"<b>&\",\n  \"id\": 1152921504606846976,\n  \"small\": 7,\n  \"inner\": {\n    \"z\": \"z\",\n    \"a\": null\n  },\n  \"list\": [\n    null,\n    -4611686018427387904\n  ]\n}", nil},
+		{"redactor keeps field order", JsonOptions{Redactor: log.DefaultRedactor, NoHTMLEscape: true}, false, `{"name":"<b>&","id":1152921504606846976,"small":7,"inner":{"z":"z","a":null},"list":[null,-4611686018427387904]}`, nil},
+		{"max size", JsonOptions{MaxSize: 32}, false, "", ErrJsonTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var err error
+			if tt.pretty {
+				got, err = JsonPrettyString(v, tt.opts)
+			} else {
+				got, err = JsonString(v, tt.opts)
+			}
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("got %s %v, want %s %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	ch := make(chan int)
+	if got := LogJsonString(ch); got != fmt.Sprintf("%+v", ch) {
+		t.Errorf("log json string: %s", got)
+	}
+	if got := LogJsonPrettyString([]int{1}, JsonOptions{Indent: " "}); got != "[\n 1\n]" {
+		t.Errorf("log json pretty string: %s", got)
+	}
+}
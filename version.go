@@ -1,6 +1,9 @@
 package tools
 
 import (
+	"bytes"
+	"cmp"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"strconv"
@@ -33,6 +36,108 @@ func (v Version) Alpha() Version {
 	return -v
 }
 
+// IsAlpha reports whether v is an alpha version
+func (v Version) IsAlpha() bool {
+	return v < 0
+}
+
+// Release returns the release version of v
+func (v Version) Release() Version {
+	return Version(Abs(int64(v)))
+}
+
+// Compare returns -1, 0 or 1. Major.minor.patch are compared first, and an alpha version is lower
+// than the release of the same major.minor.patch, so that 1.9.9 < 2.0.0.a < 2.0.0. Comparing the
+// int64 values directly is wrong because all alpha versions are negative.
+func (v Version) Compare(o Version) int {
+	if c := cmp.Compare(Abs(int64(v)), Abs(int64(o))); c != 0 {
+		return c
+	}
+	return cmp.Compare(IF(v < 0, 0, 1), IF(o < 0, 0, 1))
+}
+
+func (v Version) Less(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+// SortKey returns a non-negative int64 which has the same order as Compare, it is the form stored in
+// database by Value, while the packed int64 is not ordered when alpha versions exist.
+func (v Version) SortKey() int64 {
+	return Abs(int64(v))*2 + IF[int64](v < 0, 0, 1)
+}
+
+// VersionFromSortKey is the reverse of Version.SortKey
+func VersionFromSortKey(key int64) (Version, error) {
+	if key < 0 {
+		return 0, fmt.Errorf("tools: invalid version sort key %d", key)
+	}
+	v := Version(key / 2)
+	if _, err := NewVersion(v.Major(), v.Minor(), v.Patch(), key%2 == 0); err != nil {
+		return 0, fmt.Errorf("tools: invalid version sort key %d: %w", key, err)
+	}
+	return IF(key%2 == 0, -v, v), nil
+}
+
+func versionFromPacked(i int64) (Version, error) {
+	v := Version(i)
+	if _, err := NewVersion(v.Major(), v.Minor(), v.Patch(), v < 0); err != nil {
+		return 0, fmt.Errorf("tools: invalid packed version %d: %w", i, err)
+	}
+	return v, nil
+}
+
+type VersionPart int
+
+const (
+	VersionMajor VersionPart = iota
+	VersionMinor
+	VersionPatch
+)
+
+func (p VersionPart) String() string {
+	switch p {
+	case VersionMajor:
+		return "major"
+	case VersionMinor:
+		return "minor"
+	case VersionPatch:
+		return "patch"
+	default:
+		return "VersionPart-" + strconv.Itoa(int(p))
+	}
+}
+
+// Bump increases the part and resets the lower parts to 0, the result is a release version. Like
+// npm, an alpha version is released without increasing if the lower parts are all 0:
+//
+//	1.2.3   -> major:2.0.0 minor:1.3.0 patch:1.2.4
+//	1.2.3.a -> major:2.0.0 minor:1.3.0 patch:1.2.3
+//	1.2.0.a -> major:2.0.0 minor:1.2.0 patch:1.2.0
+//	2.0.0.a -> major:2.0.0 minor:2.0.0 patch:2.0.0
+func (v Version) Bump(part VersionPart) (Version, error) {
+	major, minor, patch := v.Major(), v.Minor(), v.Patch()
+	alpha := v.IsAlpha()
+	switch part {
+	case VersionMajor:
+		if !alpha || minor != 0 || patch != 0 {
+			major++
+		}
+		minor, patch = 0, 0
+	case VersionMinor:
+		if !alpha || patch != 0 {
+			minor++
+		}
+		patch = 0
+	case VersionPatch:
+		if !alpha {
+			patch++
+		}
+	default:
+		return 0, fmt.Errorf("tools: unknown version part %s", part)
+	}
+	return NewVersion(major, minor, patch, false)
+}
+
 func NewVersion(major, minor, patch uint64, alpha bool) (Version, error) {
 	if major > 999_999 || minor > 999_999 || patch > 999_999 {
 		return 0, errors.New("tools: out of range")
@@ -63,9 +168,90 @@ func ParseVersion(s string) (Version, error) {
 	return NewVersion(ns[0], ns[1], ns[2], len(ss) == 4)
 }
 
+func MustParseVersion(s string) Version {
+	v, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
 func (v Version) String() string {
 	if v < 0 {
 		return fmt.Sprintf("%d.%d.%d.a", v.Major(), v.Minor(), v.Patch())
 	}
 	return fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch())
 }
+
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+func (v *Version) UnmarshalText(text []byte) error {
+	ver, err := ParseVersion(string(text))
+	if err != nil {
+		return err
+	}
+	*v = ver
+	return nil
+}
+
+// UnmarshalJSON accepts both the string form "1.2.3" and the packed int64 form
+func (v *Version) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		return v.UnmarshalText([]byte(s))
+	}
+	i, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("tools: invalid version json %s: %w", data, err)
+	}
+	ver, err := versionFromPacked(i)
+	if err != nil {
+		return err
+	}
+	*v = ver
+	return nil
+}
+
+// Scan accepts the SortKey stored by Value (as int64 or a numeric string) and the string form
+func (v *Version) Scan(value any) error {
+	if v == nil {
+		return ErrNilValue
+	}
+	switch src := value.(type) {
+	case nil:
+		return ErrNilSource
+	case int64:
+		ver, err := VersionFromSortKey(src)
+		if err != nil {
+			return err
+		}
+		*v = ver
+		return nil
+	case []byte:
+		return v.scanString(string(src))
+	case string:
+		return v.scanString(src)
+	default:
+		return fmt.Errorf("tools: Version scan source was not int64, []byte or string but %T", value)
+	}
+}
+
+func (v *Version) scanString(s string) error {
+	if i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+		return v.Scan(i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// Value stores the SortKey, so that versions are ordered by Compare in database
+func (v Version) Value() (driver.Value, error) {
+	return v.SortKey(), nil
+}
+
+type NullVersion = Null[Version]
@@ -0,0 +1,305 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidVersionConstraint = errors.New("tools: invalid version constraint")
+
+type versionComparator struct {
+	op string // one of =, !=, >, >=, <, <=
+	v  Version
+}
+
+func (c versionComparator) check(v Version) bool {
+	r := v.Compare(c.v)
+	switch c.op {
+	case "=":
+		return r == 0
+	case "!=":
+		return r != 0
+	case ">":
+		return r > 0
+	case ">=":
+		return r >= 0
+	case "<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	}
+	return false
+}
+
+// VersionConstraint version constraint expression, groups are separated by "||" and comparators in
+// a group are separated by commas or spaces:
+//
+//	>=1.2.0, <2.0.0 || 3.x
+//
+// Operators: =, !=, >, >=, <, <=, ~ (~> also), ^, and no operator means =. Versions can be partial
+// (1, 1.2) or with wildcards (1.x, 1.2.*, *), and the alpha version is written as 1.2.3.a or
+// 1.2.3-alpha. Alpha versions are lower than their releases, so:
+//
+//	1.2.x   => >=1.2.0, <1.3.0.a
+//	~1.2.3  => >=1.2.3, <1.3.0.a
+//	^1.2.3  => >=1.2.3, <2.0.0.a
+//	^0.2.3  => >=0.2.3, <0.3.0.a
+//	^0.0.3  => >=0.0.3, <0.0.4.a
+//	<=1.2   => <1.3.0.a
+//	>1.2    => >=1.3.0.a
+//
+// Upper bounds generated by ranges exclude the alpha of the next version, while comparators with
+// full versions follow the order strictly (<2.0.0 matches 2.0.0.a).
+type VersionConstraint struct {
+	expr   string
+	groups [][]versionComparator
+}
+
+func ParseVersionConstraint(expr string) (*VersionConstraint, error) {
+	c := &VersionConstraint{expr: strings.TrimSpace(expr)}
+	groups := strings.Split(c.expr, "||")
+	for _, group := range groups {
+		comparators, err := parseVersionGroup(group)
+		if err == nil && len(groups) > 1 && strings.TrimSpace(group) == "" {
+			err = errors.New("empty group")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidVersionConstraint, expr, err)
+		}
+		c.groups = append(c.groups, comparators)
+	}
+	return c, nil
+}
+
+func MustParseVersionConstraint(expr string) *VersionConstraint {
+	c, err := ParseVersionConstraint(expr)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func parseVersionGroup(group string) ([]versionComparator, error) {
+	fields := strings.Fields(strings.ReplaceAll(group, ",", " "))
+	comparators := make([]versionComparator, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		term := fields[i]
+		if strings.TrimLeft(term, "=!<>~^") == "" {
+			// operator separated from the version by spaces
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("missing version after %s", term)
+			}
+			i++
+			term += fields[i]
+		}
+		cs, err := parseVersionTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, cs...)
+	}
+	return comparators, nil
+}
+
+// partialVersion a version with n (0~3) parts specified
+type partialVersion struct {
+	nums  [3]uint64
+	n     int
+	alpha bool
+}
+
+func parsePartialVersion(s string) (partialVersion, error) {
+	var p partialVersion
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	if str, ok := strings.CutSuffix(s, "-alpha"); ok {
+		s, p.alpha = str, true
+	} else if str, ok = strings.CutSuffix(s, ".a"); ok {
+		s, p.alpha = str, true
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || s == "" {
+		return p, fmt.Errorf("invalid version %q", s)
+	}
+	wildcard := false
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard {
+			return p, fmt.Errorf("version number after wildcard in %q", s)
+		}
+		u, err := strconv.ParseUint(part, 10, 64)
+		if err != nil || u > 999_999 {
+			return p, fmt.Errorf("invalid version number %q", part)
+		}
+		p.nums[i] = u
+		p.n++
+	}
+	if p.alpha && p.n != 3 {
+		return p, fmt.Errorf("alpha of partial version %q", s)
+	}
+	return p, nil
+}
+
+// lower the lowest version matches p
+func (p partialVersion) lower() (Version, error) {
+	return NewVersion(p.nums[0], p.nums[1], p.nums[2], p.alpha)
+}
+
+// next the alpha version of p with the part at index increased, which is the exclusive upper bound
+// of versions starting with p[:index+1]. ok is false if overflowed.
+func (p partialVersion) next(index int) (Version, bool) {
+	nums := [3]uint64{}
+	copy(nums[:index], p.nums[:index])
+	nums[index] = p.nums[index] + 1
+	v, err := NewVersion(nums[0], nums[1], nums[2], true)
+	return v, err == nil
+}
+
+// rangeOf returns comparators of [lower, next(index))
+func (p partialVersion) rangeOf(index int) ([]versionComparator, error) {
+	var cs []versionComparator
+	lower, err := p.lower()
+	if err != nil {
+		return nil, err
+	}
+	if lower != 0 {
+		cs = append(cs, versionComparator{">=", lower})
+	}
+	if index >= 0 {
+		if upper, ok := p.next(index); ok {
+			cs = append(cs, versionComparator{"<", upper})
+		}
+	}
+	return cs, nil
+}
+
+func parseVersionTerm(term string) ([]versionComparator, error) {
+	opLen := len(term) - len(strings.TrimLeft(term, "=!<>~^"))
+	op, vs := term[:opLen], term[opLen:]
+	p, err := parsePartialVersion(vs)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "", "=", "==":
+		if p.n == 3 {
+			v, err := p.lower()
+			if err != nil {
+				return nil, err
+			}
+			return []versionComparator{{"=", v}}, nil
+		}
+		return p.rangeOf(p.n - 1)
+	case "!=":
+		if p.n != 3 {
+			return nil, fmt.Errorf("partial version %q is not supported by !=", vs)
+		}
+		v, err := p.lower()
+		if err != nil {
+			return nil, err
+		}
+		return []versionComparator{{"!=", v}}, nil
+	case ">", "<=":
+		if p.n == 3 {
+			v, err := p.lower()
+			if err != nil {
+				return nil, err
+			}
+			return []versionComparator{{op, v}}, nil
+		}
+		if p.n == 0 {
+			if op == "<=" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("nothing is greater than %q", vs)
+		}
+		upper, ok := p.next(p.n - 1)
+		if op == ">" {
+			if !ok {
+				return nil, fmt.Errorf("nothing is greater than %q", vs)
+			}
+			return []versionComparator{{">=", upper}}, nil
+		}
+		if !ok {
+			return nil, nil
+		}
+		return []versionComparator{{"<", upper}}, nil
+	case ">=", "<":
+		if p.n == 0 {
+			if op == ">=" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("nothing is less than %q", vs)
+		}
+		v, err := p.lower()
+		if err != nil {
+			return nil, err
+		}
+		return []versionComparator{{op, v}}, nil
+	case "~", "~>":
+		switch p.n {
+		case 0:
+			return nil, nil
+		case 1:
+			return p.rangeOf(0)
+		default:
+			return p.rangeOf(1)
+		}
+	case "^":
+		switch {
+		case p.n == 0:
+			return nil, nil
+		case p.nums[0] > 0 || p.n == 1:
+			return p.rangeOf(0)
+		case p.nums[1] > 0 || p.n == 2:
+			return p.rangeOf(1)
+		default:
+			return p.rangeOf(2)
+		}
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+func (c *VersionConstraint) String() string {
+	return c.expr
+}
+
+// Check reports whether v satisfies any group of the constraint
+func (c *VersionConstraint) Check(v Version) bool {
+	for _, group := range c.groups {
+		matched := true
+		for _, comparator := range group {
+			if !comparator.check(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Highest returns the highest version in vs which satisfies the constraint
+func (c *VersionConstraint) Highest(vs ...Version) (highest Version, ok bool) {
+	for _, v := range vs {
+		if c.Check(v) && (!ok || v.Compare(highest) > 0) {
+			highest, ok = v, true
+		}
+	}
+	return highest, ok
+}
+
+// Satisfies reports whether v satisfies the constraint expression
+func (v Version) Satisfies(constraint string) (bool, error) {
+	c, err := ParseVersionConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		expr string
+		yes  []string
+		no   []string
+	}{
+		{">=1.2.0, <2.0.0", []string{"1.2.0", "1.9.9", "2.0.0.a"}, []string{"1.2.0.a", "1.1.9", "2.0.0"}},
+		{">= 1.2.0 < 2.0.0.a", []string{"1.2.0", "1.9.9"}, []string{"2.0.0.a", "2.0.0"}},
+		{"~1.4", []string{"1.4.0", "1.4.99", "1.4.5.a"}, []string{"1.4.0.a", "1.5.0.a", "1.5.0", "1.3.9"}},
+		{"~1.4.2.a", []string{"1.4.2.a", "1.4.2", "1.4.9"}, []string{"1.4.1", "1.5.0.a"}},
+		{"~>2", []string{"2.0.0", "2.9.9"}, []string{"3.0.0.a", "1.9.9"}},
+		{"^2.1.3", []string{"2.1.3", "2.9.0"}, []string{"2.1.2", "2.1.3.a", "3.0.0.a", "3.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0.a", "0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4.a", "0.0.4", "0.0.2"}},
+		{"^0", []string{"0.0.0", "0.9.9"}, []string{"1.0.0.a"}},
+		{"1.x", []string{"1.0.0", "1.99.1", "1.2.0.a"}, []string{"1.0.0.a", "2.0.0.a", "0.9.0"}},
+		{"1.2.*", []string{"1.2.0", "1.2.7"}, []string{"1.3.0.a", "1.1.0"}},
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.3.a", "1.2.4"}},
+		{"=1.2.3.a", []string{"1.2.3.a"}, []string{"1.2.3"}},
+		{"!=1.2.3", []string{"1.2.3.a", "1.2.4"}, []string{"1.2.3"}},
+		{">1.2", []string{"1.3.0.a", "1.3.0"}, []string{"1.2.99"}},
+		{"<=1.2", []string{"1.2.99", "0.1.0"}, []string{"1.3.0.a"}},
+		{"<1.2", []string{"1.1.9", "1.2.0.a"}, []string{"1.2.0"}},
+		{"1.x || >=3.0.0, <3.5.0 || 5.0.0", []string{"1.5.0", "3.4.0", "5.0.0"}, []string{"2.0.0", "3.5.0", "4.0.0", "5.0.1"}},
+		{"*", []string{"0.0.0", "999999.0.0.a"}, nil},
+		{"", []string{"1.0.0"}, nil},
+	}
+	for _, test := range tests {
+		c, err := ParseVersionConstraint(test.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", test.expr, err)
+		}
+		for _, s := range test.yes {
+			if ok, err := MustParseVersion(s).Satisfies(test.expr); err != nil || !ok {
+				t.Fatalf("%s should match %s", test.expr, s)
+			}
+		}
+		for _, s := range test.no {
+			if c.Check(MustParseVersion(s)) {
+				t.Fatalf("%s should not match %s", test.expr, s)
+			}
+		}
+	}
+
+	for _, bad := range []string{">", "1.x.3", "!=1.2", ">*", "<*", "%1.2.3", "1.2.a", "1.2.3.4", "0.0.0.a", "1000000.0.0", "1.2 ||"} {
+		if _, err := ParseVersionConstraint(bad); !errors.Is(err, ErrInvalidVersionConstraint) {
+			t.Fatalf("%q should be invalid: %v", bad, err)
+		}
+	}
+}
+
+func TestVersionConstraint_Highest(t *testing.T) {
+	var vs []Version
+	for _, s := range []string{"1.2.0", "1.9.0", "2.0.0.a", "1.9.1.a", "2.1.0", "1.10.0.a"} {
+		vs = append(vs, MustParseVersion(s))
+	}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"^1.2", "1.10.0.a"},
+		{"~1.9", "1.9.1.a"},
+		{">=1.0.0, <2.0.0 || 2.x", "2.1.0"},
+		{"<2.0.0", "2.0.0.a"},
+		{"3.x", ""},
+	}
+	for _, test := range tests {
+		v, ok := MustParseVersionConstraint(test.expr).Highest(vs...)
+		if test.want == "" {
+			if ok {
+				t.Fatalf("%s: should not found, but %s", test.expr, v)
+			}
+			continue
+		}
+		if !ok || v.String() != test.want {
+			t.Fatalf("%s: got %s %t, want %s", test.expr, v, ok, test.want)
+		}
+	}
+}
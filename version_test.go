@@ -1,6 +1,11 @@
 package tools
 
-import "testing"
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"testing"
+)
 
 func TestNodeVersion(t *testing.T) {
 	tests := []struct {
@@ -99,3 +104,119 @@ func TestVersionString(t *testing.T) {
 		}
 	}
 }
+
+func TestVersionSQLAndJSON(t *testing.T) {
+	type doc struct {
+		V Version     `json:"v"`
+		N NullVersion `json:"n"`
+	}
+	d := doc{V: -1_000_002_000_003, N: NewNull[Version](2_000_000_000_000)}
+	bs, err := json.Marshal(d)
+	if err != nil || string(bs) != `{"v":"1.2.3.a","n":"2.0.0"}` {
+		t.Fatalf("marshal: %s %v", bs, err)
+	}
+	var got doc
+	if err = json.Unmarshal([]byte(`{"v":-1000002000003,"n":null}`), &got); err != nil || got.V != d.V || got.N.Valid {
+		t.Fatalf("unmarshal packed: %+v %v", got, err)
+	}
+	if err = json.Unmarshal(bs, &got); err != nil || got != d {
+		t.Fatalf("unmarshal: %+v %v", got, err)
+	}
+
+	if dv, err := d.V.Value(); err != nil || dv != int64(2_000_004_000_006) {
+		t.Fatalf("value: %v %v", dv, err)
+	}
+	tests := []struct {
+		src  any
+		want Version
+		err  bool
+	}{
+		{int64(14_000_004_000_013), 7_000_002_000_006, false},
+		{int64(14_000_004_000_012), -7_000_002_000_006, false},
+		{int64(1), 0, false},
+		{[]byte("7.2.6.a"), -7_000_002_000_006, false},
+		{"14000004000013", 7_000_002_000_006, false},
+		{int64(1 << 62), 0, true},
+		{int64(-3), 0, true},
+		{int64(0), 0, true},
+		{"x.y", 0, true},
+		{nil, 0, true},
+		{1.5, 0, true},
+	}
+	for _, test := range tests {
+		var v Version
+		err := v.Scan(test.src)
+		if (err != nil) != test.err || v != test.want {
+			t.Fatalf("scan %v: got %s %v", test.src, v, err)
+		}
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	ordered := []string{"0.0.0", "0.0.1.a", "0.0.1", "0.1.0", "1.9.9", "2.0.0.a", "2.0.0", "2.0.1.a", "2.0.1", "10.0.0.a"}
+	vs := make([]Version, len(ordered))
+	for i, s := range ordered {
+		vs[i] = MustParseVersion(s)
+	}
+	for i := range vs {
+		for j := range vs {
+			want := cmp.Compare(i, j)
+			if got := vs[i].Compare(vs[j]); got != want {
+				t.Fatalf("%s compare %s: got %d, want %d", vs[i], vs[j], got, want)
+			}
+			if got := cmp.Compare(vs[i].SortKey(), vs[j].SortKey()); got != want {
+				t.Fatalf("sort key %s compare %s: got %d, want %d", vs[i], vs[j], got, want)
+			}
+		}
+	}
+	// values stored in database are sorted like Compare
+	stored := make([]int64, len(vs))
+	for i, v := range vs {
+		dv, _ := v.Value()
+		stored[len(vs)-1-i] = dv.(int64)
+	}
+	slices.Sort(stored)
+	for i, dv := range stored {
+		var v Version
+		if err := v.Scan(dv); err != nil || v.Compare(vs[i]) != 0 {
+			t.Fatalf("stored %d: %s %v, want %s", i, v, err, vs[i])
+		}
+	}
+	shuffled := slices.Clone(vs)
+	slices.Reverse(shuffled)
+	slices.SortFunc(shuffled, Version.Compare)
+	if !slices.Equal(shuffled, vs) {
+		t.Fatalf("sorted: %v", shuffled)
+	}
+	if v := MustParseVersion("2.0.0.a"); !v.IsAlpha() || v.Release().String() != "2.0.0" || v.Release().IsAlpha() || !v.Less(v.Release()) {
+		t.Fatalf("alpha %s", v)
+	}
+}
+
+func TestVersion_Bump(t *testing.T) {
+	tests := []struct {
+		v                   string
+		major, minor, patch string
+	}{
+		{"1.2.3", "2.0.0", "1.3.0", "1.2.4"},
+		{"1.2.3.a", "2.0.0", "1.3.0", "1.2.3"},
+		{"1.2.0.a", "2.0.0", "1.2.0", "1.2.0"},
+		{"2.0.0.a", "2.0.0", "2.0.0", "2.0.0"},
+		{"0.0.0", "1.0.0", "0.1.0", "0.0.1"},
+	}
+	for _, test := range tests {
+		v := MustParseVersion(test.v)
+		for part, want := range []string{test.major, test.minor, test.patch} {
+			got, err := v.Bump(VersionPart(part))
+			if err != nil || got.String() != want {
+				t.Fatalf("bump %s of %s: got %s %v, want %s", VersionPart(part), v, got, err, want)
+			}
+		}
+	}
+	if _, err := MustParseVersion("1.2.999999").Bump(VersionPatch); err == nil {
+		t.Fatal("overflow should fail")
+	}
+	if _, err := MustParseVersion("1.2.3").Bump(VersionPart(3)); err == nil {
+		t.Fatal("unknown part should fail")
+	}
+}